@@ -1,9 +1,46 @@
 package main
 
 import (
-	"log"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"github.com/fiap-161/tc-golunch-payment-service/database"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/health"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/http/middleware"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/controllers"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/external/datasource"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/handlers"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/usecases"
+	qrcodegateways "github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/gateways"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/authz"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/config"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/credentials"
+	authgateway "github.com/fiap-161/tc-golunch-payment-service/internal/shared/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/httpclient"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/logger"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/metrics"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/mtls"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/ratelimit"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/signing"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/tracing"
+	storedatasource "github.com/fiap-161/tc-golunch-payment-service/internal/store/external/datasource"
+	storegateway "github.com/fiap-161/tc-golunch-payment-service/internal/store/gateway"
 )
 
 // @title           GoLunch Payment Service API
@@ -12,62 +49,441 @@ import (
 // @host            localhost:8082
 // @BasePath        /
 func main() {
-	r := gin.Default()
+	logger.Setup("")
+	cfg := loadConfig()
+	logger.Setup(cfg.Log.Level)
+	shutdownTracing := setupTracing(cfg.Tracing)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	background := newWorkers()
+	rotating := loadSecrets(cfg, background)
+
+	mongoDB := database.NewMongoDatabase(cfg.MongoDB.URI, cfg.MongoDB.Database)
+	coreServiceURL := cfg.Core.URL
+
+	breakers := circuitbreaker.NewRegistry(circuitbreaker.Settings{
+		FailureThreshold: cfg.Resilience.CircuitBreaker.FailureThreshold,
+		OpenTimeout:      cfg.Resilience.CircuitBreaker.OpenTimeout,
+		HalfOpenMaxCalls: cfg.Resilience.CircuitBreaker.HalfOpenMaxCalls,
+	})
+
+	appMetrics := metrics.New()
+	coreTLS := clientTLSConfig(cfg.MTLS.Client)
+	outbound := func(dependency string) func(http.RoundTripper) http.RoundTripper {
+		instrument := appMetrics.InstrumentTransport(dependency)
+		return func(base http.RoundTripper) http.RoundTripper {
+			return tracing.Transport(instrument(base))
+		}
+	}
+	coreOptions := func(dependency string) []httpclient.Option {
+		return coreClientOptions(rotating, breakers.Get(dependency), coreTLS, outbound(dependency))
+	}
+
+	ensurePaymentIndexes(mongoDB.GetDatabase())
+	paymentGateway := gateway.Build(datasource.NewMongo(mongoDB.GetDatabase()))
+	paymentUseCase := usecases.Build(
+		paymentGateway,
+		qrcodegateways.WithBreaker(
+			qrcodegateways.New(cfg.Providers.MercadoPago,
+				qrcodegateways.WithAccessToken(rotating.mercadoPagoToken),
+				qrcodegateways.WithCollectorTokens(rotating.collectorTokens),
+				qrcodegateways.WithTransport(outbound("mercadopago")),
+			),
+			breakers.Get("mercadopago"),
+		),
+		httpclient.NewProductClient(coreServiceURL, coreOptions("product")...),
+		httpclient.NewProductOrderClient(coreServiceURL, coreOptions("product-order")...),
+		httpclient.NewCoreClient(coreServiceURL, coreOptions("core")...),
+		storegateway.Build(storedatasource.NewMongo(mongoDB.GetDatabase())),
+		usecases.WithMetrics(appMetrics),
+	)
+	paymentHandler := handlers.New(controllers.Build(paymentUseCase))
+
+	authGateway := authgateway.NewServerlessAuthGateway(
+		cfg.Auth.Lambda.TokenURL,
+		cfg.Auth.Lambda.ServiceURL,
+		append(authOptions(cfg, mongoDB.GetDatabase(), background), authgateway.WithTransport(outbound("auth")))...,
+	)
+	appMetrics.WatchTokenCache(authGateway)
+	appMetrics.WatchBreakers(breakers)
+	healthHandler := health.New(
+		health.WithCheckTimeout(cfg.Health.CheckTimeout),
+		health.WithComponents(readinessComponents(cfg, rotating.mercadoPagoToken, mongoDB.GetClient(), breakers, coreTLS)...),
+	)
+	policy := authz.NewPolicy(cfg.Authz.Roles)
+
+	limiter := ratelimit.NewLimiter(rateLimitBackend(cfg.RateLimit.Backend, mongoDB.GetDatabase()))
+	limits := cfg.RateLimit.Groups
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("invalid trusted proxies", err)
+	}
+	r.Use(gin.Recovery(), otelgin.Middleware(cfg.Tracing.ServiceName), middleware.RequestID(), middleware.AccessLog(), appMetrics.Middleware())
 
 	// Default Routes
 	r.GET("/ping", ping)
+	r.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	r.GET("/health/live", healthHandler.Live)
+	r.GET("/health/ready", healthHandler.Ready)
+
+	// Provider Webhooks
+	r.POST("/webhook/payment/check",
+		middleware.RateLimit(limiter, shared.RateLimitGroupWebhooks, limits[shared.RateLimitGroupWebhooks]),
+		middleware.WebhookSignature(rotating.webhookSecret, webhookOptions(cfg.Providers.MercadoPago)...),
+		paymentHandler.CheckPayment,
+	)
+
+	// Authenticated Routes
+	var credentials []middleware.Credential
+	if cfg.MTLS.Server.Enabled {
+		credentials = append(credentials, middleware.ClientCert(mtls.NewIdentities(cfg.MTLS.Server.Identities)))
+	}
+	credentials = append(credentials,
+		middleware.ServiceKey(authGateway, serviceAuthOptions(cfg.ServiceAuth.Signing, rotating.signingSecrets, mongoDB.GetDatabase())...),
+		middleware.UserJWT(authGateway),
+	)
+	serviceOrUser := middleware.RequireAny(credentials...)
+	userOnly := middleware.RequireAny(middleware.UserJWT(authGateway))
 
-	// Payment Routes (simplificado para funcionar)
-	r.POST("/payments", createPayment)
-	r.POST("/webhook/payment/check", checkPayment)
+	paymentsAPILimit := middleware.RateLimit(limiter, shared.RateLimitGroupPaymentsAPI, limits[shared.RateLimitGroupPaymentsAPI])
 
-	log.Println("Payment Service starting on port 8082...")
-	r.Run(":8082")
+	authenticated := r.Group("/payments")
+	authenticated.POST("", serviceOrUser, middleware.RateLimit(limiter, shared.RateLimitGroupPaymentsCreate, limits[shared.RateLimitGroupPaymentsCreate]), middleware.RequirePermissions(policy, authz.PermissionPaymentsCreate), paymentHandler.Create)
+	authenticated.GET("/:id", serviceOrUser, paymentsAPILimit, middleware.RequirePermissions(policy, authz.PermissionPaymentsRead), paymentHandler.FindByID)
+	authenticated.GET("/:id/qrcode.png", serviceOrUser, paymentsAPILimit, middleware.RequirePermissions(policy, authz.PermissionPaymentsRead), paymentHandler.QRCodePNG)
+	authenticated.GET("/:id/qrcode.svg", serviceOrUser, paymentsAPILimit, middleware.RequirePermissions(policy, authz.PermissionPaymentsRead), paymentHandler.QRCodeSVG)
+	authenticated.POST("/:id/cash/confirm", userOnly, paymentsAPILimit, middleware.RequirePermissions(policy, authz.PermissionPaymentsCashConfirm), paymentHandler.ConfirmCashPayment)
+
+	server := &http.Server{Addr: cfg.Server.Addr(), Handler: r}
+	mtlsEnabled := cfg.MTLS.Server.Enabled
+	if mtlsEnabled {
+		server.TLSConfig = serverTLSConfig(cfg.MTLS.Server)
+	}
+
+	slog.Info("payment service starting", "port", cfg.Server.Port, "mtls", mtlsEnabled)
+	select {
+	case err := <-listen(server, mtlsEnabled):
+		fatal("server stopped", err)
+	case <-ctx.Done():
+	}
+
+	shutdown(server, healthHandler, cfg.Server, background, mongoDB, shutdownTracing)
+	slog.Info("payment service stopped")
 }
 
-// Ping godoc
-// @Summary      Answers with "pong"
-// @Description  Health Check
-// @Tags         Ping
-// @Accept       json
-// @Produce      json
-// @Success      200 {object}  map[string]string
-// @Router       /ping [get]
-func ping(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"message": "pong",
+// loadConfig reads the configuration from the YAML file, environment and
+// flags, and exits listing every problem when it is not valid.
+func loadConfig() config.Config {
+	cfg, err := config.Load("payment-service", os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fatal("failed to load configuration", err)
+	}
+
+	var validationErr *config.ValidationError
+	if errors.As(cfg.Validate(), &validationErr) {
+		slog.Error("invalid configuration", "problems", validationErr.Problems)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// setupTracing installs W3C trace context propagation and, when
+// app.tracing.enabled is set, span export over OTLP.
+func setupTracing(cfg config.Tracing) func(context.Context) error {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:     cfg.Enabled,
+		ServiceName: cfg.ServiceName,
+		Endpoint:    cfg.Endpoint,
+		Insecure:    cfg.Insecure,
+		SampleRatio: cfg.SampleRatio,
 	})
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
+	return shutdown
 }
 
-// Create Payment endpoint (simples para testes)
-func createPayment(c *gin.Context) {
-	var request map[string]interface{}
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request"})
-		return
+// readinessComponents lists what /health/ready checks. MongoDB and the
+// settings every payment needs are critical, the same ones config.Validate
+// requires at startup: the default POS only when one is configured for the
+// current mode. Core and the breakers only degrade the report.
+func readinessComponents(cfg config.Config, mercadoPagoToken *secrets.Secret, client *mongo.Client, breakers *circuitbreaker.Registry, coreTLS *tls.Config) []health.Component {
+	coreClient := &http.Client{Transport: &http.Transport{TLSClientConfig: coreTLS}}
+
+	required := map[string]*secrets.Secret{
+		"MERCADO_PAGO_ACCESS_TOKEN": mercadoPagoToken,
+		"CORE_SERVICE_URL":          secrets.Static("core service url", cfg.Core.URL),
+	}
+	for env, value := range cfg.Providers.MercadoPago.DefaultPOS() {
+		required[env] = secrets.Static(env, value)
 	}
 
-	// Simular criação de pagamento
-	c.JSON(200, gin.H{
-		"payment_id": "payment_123",
-		"qr_code":    "mock-qr-code-data",
-		"status":     "pending",
-		"order_id":   request["order_id"],
+	return []health.Component{
+		{Name: "mongodb", Check: health.MongoCheck(client), Critical: true},
+		{Name: "config", Check: health.ConfigCheck(required), Critical: true},
+		{Name: "core", Check: health.HTTPCheck(coreClient, cfg.Core.URL+cfg.Health.CorePath)},
+		{Name: "circuit_breakers", Check: health.BreakersCheck(breakers)},
+	}
+}
+
+// runtimeSecrets are the credentials that can be rotated through mounted
+// files without restarting the pod.
+type runtimeSecrets struct {
+	mercadoPagoToken *secrets.Secret
+	coreAPIKey       *secrets.Secret
+	webhookSecret    *secrets.Secret
+	collectorTokens  secrets.Set
+	// signingSecret signs outbound calls to Core and signingSecrets holds
+	// the YAML file of the callers' secrets; each is nil when unused.
+	signingSecret  *secrets.Secret
+	signingSecrets *secrets.Secret
+}
+
+// loadSecrets reads the rotatable credentials, preferring their *_file
+// settings, and re-reads the files every app.secrets.reload_interval.
+func loadSecrets(cfg config.Config, background *workers) runtimeSecrets {
+	mercadoPago := cfg.Providers.MercadoPago
+	loaded := runtimeSecrets{
+		mercadoPagoToken: loadSecret("mercado pago access token", mercadoPago.AccessToken, mercadoPago.AccessTokenFile),
+		coreAPIKey:       loadSecret("core api key", cfg.Core.APIKey, cfg.Core.APIKeyFile),
+		webhookSecret:    loadSecret("mercado pago webhook secret", mercadoPago.WebhookSecret, mercadoPago.WebhookSecretFile),
+		collectorTokens:  secrets.Set{},
+	}
+	for ref, source := range mercadoPago.CollectorTokens {
+		loaded.collectorTokens[ref] = loadSecret("collector token "+ref, source.Value, source.File)
+	}
+	signingCfg := cfg.ServiceAuth.Signing
+	if signingCfg.Secret != "" || signingCfg.SecretFile != "" {
+		loaded.signingSecret = loadSecret("service signing secret", signingCfg.Secret, signingCfg.SecretFile)
+	}
+	if signingCfg.Enabled {
+		loaded.signingSecrets = loadSecret("service signing secrets", "", signingCfg.SecretsFile)
+	}
+
+	for _, secret := range []*secrets.Secret{loaded.mercadoPagoToken, loaded.coreAPIKey, loaded.webhookSecret, loaded.signingSecret, loaded.signingSecrets} {
+		background.Go(func(ctx context.Context) {
+			secret.Watch(ctx, cfg.Secrets.ReloadInterval)
+		})
+	}
+	background.Go(func(ctx context.Context) {
+		loaded.collectorTokens.Watch(ctx, cfg.Secrets.ReloadInterval)
 	})
+	return loaded
 }
 
-// Check Payment webhook (simples para testes)
-func checkPayment(c *gin.Context) {
-	var request map[string]interface{}
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(400, gin.H{"error": "Invalid request"})
-		return
+func loadSecret(name, value, path string) *secrets.Secret {
+	secret, err := secrets.Load(name, value, path)
+	if err != nil {
+		fatal("failed to load "+name, err)
 	}
+	return secret
+}
 
-	// Simular verificação de pagamento
-	c.JSON(200, gin.H{
-		"status":  "approved",
-		"message": "Payment verified successfully",
+// authOptions enables the token cache and, when app.auth.mode is local,
+// local JWT validation; otherwise tokens keep going to the auth Lambda.
+func authOptions(cfg config.Config, db *mongo.Database, background *workers) []authgateway.Option {
+	auth := cfg.Auth
+	opts := []authgateway.Option{authgateway.WithTimeout(auth.Lambda.Timeout)}
+	if store := serviceCredentials(cfg.ServiceAuth.Credentials, db, background); store != nil {
+		opts = append(opts, authgateway.WithServiceCredentials(store))
+	}
+	if auth.Cache.MaxEntries > 0 {
+		opts = append(opts, authgateway.WithTokenCache(authgateway.NewTokenCache(authgateway.TokenCacheSettings{
+			MaxEntries:  auth.Cache.MaxEntries,
+			TTL:         auth.Cache.TTL,
+			NegativeTTL: auth.Cache.NegativeTTL,
+		})))
+	}
+
+	if auth.Mode != shared.AuthModeLocal {
+		return opts
+	}
+
+	jwtConfig := authgateway.JWTConfig{
+		JWKSURL:     auth.JWT.JWKSURL,
+		JWKSRefresh: auth.JWT.JWKSRefresh,
+		HMACSecret:  []byte(auth.JWT.HMACSecret),
+		Leeway:      auth.JWT.Leeway,
+	}
+	if path := auth.JWT.PublicKeyFile; path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			fatal("failed to read JWT public key", err)
+		}
+		jwtConfig.PublicKeyPEM = pem
+	}
+
+	validator, err := authgateway.NewJWTValidator(jwtConfig)
+	if err != nil {
+		fatal("failed to configure JWT validation", err)
+	}
+	return append(opts, authgateway.WithLocalValidation(validator))
+}
+
+// coreClientOptions guards a Core client with its breaker, instruments its
+// calls, presents the mTLS client certificate when configured and, when
+// app.service_auth.signing.secret or secret_file is set, signs its requests.
+func coreClientOptions(rotating runtimeSecrets, breaker *circuitbreaker.Breaker, tlsConfig *tls.Config, instrument func(http.RoundTripper) http.RoundTripper) []httpclient.Option {
+	opts := []httpclient.Option{
+		httpclient.WithBreaker(breaker),
+		httpclient.WithTransport(instrument),
+		httpclient.WithServiceKey(rotating.coreAPIKey),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, httpclient.WithTLSConfig(tlsConfig))
+	}
+	if rotating.signingSecret != nil {
+		opts = append(opts, httpclient.WithSigner(signing.NewRotatingSigner(rotating.signingSecret)))
+	}
+	return opts
+}
+
+// clientTLSConfig loads the client certificate presented to the Core service
+// when app.mtls.client.enabled is set.
+func clientTLSConfig(cfg config.MTLSClient) *tls.Config {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	tlsConfig, err := mtls.NewClientTLSConfig(mtls.ClientConfig{
+		CertFile: cfg.CertFile,
+		KeyFile:  cfg.KeyFile,
+		CAFile:   cfg.CAFile,
+	})
+	if err != nil {
+		fatal("failed to configure mTLS client", err)
+	}
+	return tlsConfig
+}
+
+func serverTLSConfig(cfg config.MTLSServer) *tls.Config {
+	tlsConfig, err := mtls.NewServerTLSConfig(mtls.ServerConfig{
+		CertFile:          cfg.CertFile,
+		KeyFile:           cfg.KeyFile,
+		ClientCAFile:      cfg.ClientCAFile,
+		RequireClientCert: cfg.RequireClientCert,
+	})
+	if err != nil {
+		fatal("failed to configure mTLS server", err)
+	}
+	return tlsConfig
+}
+
+// serviceAuthOptions enables signature verification of service requests
+// when app.service_auth.signing.enabled is set. The callers' secrets follow
+// their file as it is reloaded.
+func serviceAuthOptions(cfg config.ServiceSigning, secretsFile *secrets.Secret, db *mongo.Database) []middleware.ServiceAuthOption {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	callerSecrets, err := signing.NewRotatingSecrets(secretsFile)
+	if err != nil {
+		fatal("failed to load signing secrets", err)
+	}
+	verifier := signing.NewVerifier(callerSecrets.Lookup, cfg.MaxSkew, nonceStore(cfg.NonceBackend, db))
+	return []middleware.ServiceAuthOption{
+		middleware.WithSignatureVerification(verifier, cfg.Required),
+	}
+}
+
+// nonceStore picks where replay-protection nonces live: in memory per
+// replica or in MongoDB, shared across replicas.
+func nonceStore(backend string, db *mongo.Database) signing.NonceStore {
+	if backend != shared.SigningNonceBackendMongo {
+		return signing.NewMemoryNonceStore()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	store, err := signing.NewMongoNonceStore(ctx, db)
+	if err != nil {
+		fatal("failed to set up signing nonce store", err)
+	}
+	return store
+}
+
+// serviceCredentials loads the hashed service API keys and keeps reloading
+// them so keys can be rotated without a redeploy.
+func serviceCredentials(cfg config.ServiceCredentials, db *mongo.Database, background *workers) *credentials.Store {
+	var source credentials.Source
+	switch cfg.Source {
+	case shared.ServiceCredentialsSourceFile:
+		source = credentials.NewFileSource(cfg.File)
+	case shared.ServiceCredentialsSourceMongo:
+		source = credentials.NewMongoSource(db)
+	default:
+		return nil
+	}
+
+	store := credentials.NewStore(source)
+	if err := store.Reload(context.Background()); err != nil {
+		fatal("failed to load service credentials", err)
+	}
+	background.Go(func(ctx context.Context) {
+		store.Watch(ctx, cfg.ReloadInterval)
 	})
+	return store
+}
+
+// webhookOptions configures the webhook signature check; unsigned
+// notifications are only accepted when explicitly allowed.
+func webhookOptions(cfg config.MercadoPago) []middleware.WebhookOption {
+	opts := []middleware.WebhookOption{middleware.WithWebhookTolerance(cfg.WebhookTolerance)}
+	if cfg.WebhookAllowUnsigned {
+		slog.Warn("accepting unsigned Mercado Pago webhooks")
+		opts = append(opts, middleware.WithUnsignedWebhooks())
+	}
+	return opts
+}
+
+// ensurePaymentIndexes creates the payments indexes, including the unique
+// order_id one that keeps an order from holding two open payments.
+func ensurePaymentIndexes(db *mongo.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := datasource.EnsureIndexes(ctx, db); err != nil {
+		fatal("failed to create payment indexes", err)
+	}
+}
+
+// rateLimitBackend picks where token buckets live: in memory per replica or
+// in MongoDB, shared across replicas.
+func rateLimitBackend(backend string, db *mongo.Database) ratelimit.Backend {
+	if backend != shared.RateLimitBackendMongo {
+		return ratelimit.NewMemoryBackend()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	mongoBackend, err := ratelimit.NewMongoBackend(ctx, db)
+	if err != nil {
+		fatal("failed to set up rate limit backend", err)
+	}
+	return mongoBackend
 }
 
+// fatal logs the startup error and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// Ping godoc
+// @Summary      Answers with "pong"
+// @Description  Health Check
+// @Tags         Ping
+// @Accept       json
+// @Produce      json
+// @Success      200 {object}  map[string]string
+// @Router       /ping [get]
+func ping(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"message": "pong",
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fiap-161/tc-golunch-payment-service/database"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/health"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/config"
+)
+
+// workers runs the service's background goroutines so shutdown can stop
+// them and wait for them to return.
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newWorkers() *workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workers{ctx: ctx, cancel: cancel}
+}
+
+// Go starts fn; its context is canceled on Stop.
+func (w *workers) Go(fn func(ctx context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		fn(w.ctx)
+	}()
+}
+
+// Stop cancels the workers and waits for them until ctx is done.
+func (w *workers) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// listen starts the server in the background; the channel receives the
+// error it stops with.
+func listen(server *http.Server, tls bool) <-chan error {
+	serverErr := make(chan error, 1)
+	go func() {
+		if tls {
+			serverErr <- server.ListenAndServeTLS("", "")
+			return
+		}
+		serverErr <- server.ListenAndServe()
+	}()
+	return serverErr
+}
+
+// cleanupTimeout bounds each step run after the drain, so a drain that
+// used its whole deadline still leaves time to flush traces and close the
+// Mongo client.
+const cleanupTimeout = 5 * time.Second
+
+// shutdown first fails the readiness probe and waits settings.ShutdownDelay,
+// so the pod leaves the Service endpoints while it still accepts
+// connections. It then stops accepting them and waits up to
+// settings.ShutdownTimeout for in-flight requests, so a webhook is not cut
+// off between approving the payment and notifying Core; requests still
+// running after that are cut off. Finally it stops the workers and closes
+// the Mongo client and the tracer, each under its own cleanupTimeout.
+func shutdown(server *http.Server, readiness *health.Handler, settings config.Server, background *workers, mongoDB *database.MongoDatabase, shutdownTracing func(context.Context) error) {
+	readiness.Drain()
+	slog.Info("shutting down", "delay", settings.ShutdownDelay, "timeout", settings.ShutdownTimeout)
+	time.Sleep(settings.ShutdownDelay)
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), settings.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(drainCtx); err != nil {
+		slog.Error("failed to drain in-flight requests", "error", err)
+		server.Close()
+	}
+
+	cleanup("failed to stop background workers", background.Stop)
+	cleanup("failed to close MongoDB client", mongoDB.Close)
+	cleanup("failed to flush traces", shutdownTracing)
+}
+
+// cleanup runs a shutdown step under its own cleanupTimeout.
+func cleanup(failure string, step func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if err := step(ctx); err != nil {
+		slog.Error(failure, "error", err)
+	}
+}
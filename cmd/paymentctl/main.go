@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fiap-161/tc-golunch-payment-service/database"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	qrcodegateways "github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/gateways"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/config"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/credentials"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+	storedatasource "github.com/fiap-161/tc-golunch-payment-service/internal/store/external/datasource"
+	storegateway "github.com/fiap-161/tc-golunch-payment-service/internal/store/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/usecases"
+)
+
+const usage = `paymentctl manages Mercado Pago stores, POS terminals and service API keys.
+
+Usage:
+  paymentctl store create -name NAME -external-id ID [-collector USER_ID -credentials-ref REF] [location flags]
+  paymentctl store update -id STORE_ID [-name NAME] [location flags]
+  paymentctl store list   [-collector USER_ID -credentials-ref REF]
+  paymentctl pos create   -store STORE_ID -name NAME -external-id ID [-credentials-ref REF]
+  paymentctl pos update   -id POS_ID [-name NAME] [-credentials-ref REF]
+  paymentctl pos list     [-external-store-id ID] [-credentials-ref REF]
+  paymentctl servicekey generate -service NAME [-id KEY_ID] [-expires-in DURATION] [-scopes a,b]
+
+The collector defaults to MERCADO_PAGO_SELLER_APP_USER_ID and requests are
+authenticated with MERCADO_PAGO_ACCESS_TOKEN (or the file named by
+MERCADO_PAGO_ACCESS_TOKEN_FILE). Another collector is managed with its own
+token, selected by a credentials ref that must be one of
+app.providers.mercadopago.collector_tokens; a store keeps its ref for
+updates and as the default of its POS, and a POS is provisioned with its ref.
+`
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if os.Args[1]+" "+os.Args[2] == "servicekey generate" {
+		if err := generateServiceKey(os.Args[3:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg, err := config.Load("paymentctl", nil)
+	if err != nil {
+		log.Fatal("Failed to load config: ", err)
+	}
+	mercadoPago := cfg.Providers.MercadoPago
+	accessToken, err := secrets.Load("mercado pago access token", mercadoPago.AccessToken, mercadoPago.AccessTokenFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mercadoPago.AccessToken = accessToken.Value()
+	collectorTokens := secrets.Set{}
+	for ref, source := range mercadoPago.CollectorTokens {
+		if collectorTokens[ref], err = secrets.Load("collector token "+ref, source.Value, source.File); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	mongoDB := database.NewMongoDatabase(cfg.MongoDB.URI, cfg.MongoDB.Database)
+	storeUseCase := usecases.Build(
+		storegateway.Build(storedatasource.NewMongo(mongoDB.GetDatabase())),
+		qrcodegateways.NewStoreProvisioner(mercadoPago, qrcodegateways.WithCollectorTokens(collectorTokens)),
+	)
+	collectorUserID := mercadoPago.SellerUserID
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	switch os.Args[1] + " " + os.Args[2] {
+	case "store create":
+		err = createStore(ctx, storeUseCase, collectorUserID, mercadoPago.CollectorTokens, os.Args[3:])
+	case "store update":
+		err = updateStore(ctx, storeUseCase, os.Args[3:])
+	case "store list":
+		err = listStores(ctx, storeUseCase, collectorUserID, mercadoPago.CollectorTokens, os.Args[3:])
+	case "pos create":
+		err = createPOS(ctx, storeUseCase, mercadoPago.CollectorTokens, os.Args[3:])
+	case "pos update":
+		err = updatePOS(ctx, storeUseCase, mercadoPago.CollectorTokens, os.Args[3:])
+	case "pos list":
+		err = listPOS(ctx, storeUseCase, mercadoPago.CollectorTokens, os.Args[3:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+var locationFlagNames = map[string]bool{
+	"street-number": true, "street-name": true, "city": true, "state": true,
+	"lat": true, "lng": true, "reference": true,
+}
+
+// locationFlags registers the location flags. The returned func gives the
+// parsed location, or nil when none of them was passed.
+func locationFlags(fs *flag.FlagSet) func() *entities.Location {
+	location := &entities.Location{}
+	fs.StringVar(&location.StreetNumber, "street-number", "", "street number")
+	fs.StringVar(&location.StreetName, "street-name", "", "street name")
+	fs.StringVar(&location.CityName, "city", "", "city name")
+	fs.StringVar(&location.StateName, "state", "", "state name")
+	fs.Float64Var(&location.Latitude, "lat", 0, "latitude")
+	fs.Float64Var(&location.Longitude, "lng", 0, "longitude")
+	fs.StringVar(&location.Reference, "reference", "", "location reference")
+
+	return func() *entities.Location {
+		set := false
+		fs.Visit(func(f *flag.Flag) { set = set || locationFlagNames[f.Name] })
+		if !set {
+			return nil
+		}
+		return location
+	}
+}
+
+func createStore(ctx context.Context, u *usecases.UseCases, defaultCollector string, collectorTokens map[string]config.SecretSource, args []string) error {
+	params := entities.ProvisionStoreParams{}
+	fs := flag.NewFlagSet("store create", flag.ExitOnError)
+	fs.StringVar(&params.Name, "name", "", "store name")
+	fs.StringVar(&params.ExternalID, "external-id", "", "our identifier for the store on Mercado Pago")
+	fs.StringVar(&params.CollectorUserID, "collector", defaultCollector, "Mercado Pago collector user ID")
+	fs.StringVar(&params.CredentialsRef, "credentials-ref", "", "collector token holding the collector access token (optional)")
+	location := locationFlags(fs)
+	_ = fs.Parse(args)
+	params.Location = location()
+
+	if err := checkCredentialsRef(collectorTokens, params.CredentialsRef); err != nil {
+		return err
+	}
+
+	store, err := u.CreateStore(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("store %s created (mercado pago id %s)\n", store.ID, store.ProviderStoreID)
+	return nil
+}
+
+func updateStore(ctx context.Context, u *usecases.UseCases, args []string) error {
+	var storeID string
+	params := entities.ProvisionStoreParams{}
+	fs := flag.NewFlagSet("store update", flag.ExitOnError)
+	fs.StringVar(&storeID, "id", "", "store ID")
+	fs.StringVar(&params.Name, "name", "", "new store name")
+	location := locationFlags(fs)
+	_ = fs.Parse(args)
+	params.Location = location()
+
+	store, err := u.UpdateStore(ctx, storeID, params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("store %s updated\n", store.ID)
+	return nil
+}
+
+func listStores(ctx context.Context, u *usecases.UseCases, defaultCollector string, collectorTokens map[string]config.SecretSource, args []string) error {
+	var collectorUserID, credentialsRef string
+	fs := flag.NewFlagSet("store list", flag.ExitOnError)
+	fs.StringVar(&collectorUserID, "collector", defaultCollector, "Mercado Pago collector user ID")
+	fs.StringVar(&credentialsRef, "credentials-ref", "", "collector token holding the collector access token (optional)")
+	_ = fs.Parse(args)
+
+	if err := checkCredentialsRef(collectorTokens, credentialsRef); err != nil {
+		return err
+	}
+
+	provisioned, local, err := u.ListStores(ctx, collectorUserID, credentialsRef)
+	if err != nil {
+		return err
+	}
+
+	localIDs := make(map[string]string, len(local))
+	for _, store := range local {
+		localIDs[store.ProviderStoreID] = store.ID
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MP ID\tEXTERNAL ID\tNAME\tLOCAL ID")
+	for _, store := range provisioned {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", store.ID, store.ExternalID, store.Name, orDash(localIDs[store.ID]))
+	}
+	return w.Flush()
+}
+
+func createPOS(ctx context.Context, u *usecases.UseCases, collectorTokens map[string]config.SecretSource, args []string) error {
+	var storeID, name, externalID, credentialsRef string
+	fs := flag.NewFlagSet("pos create", flag.ExitOnError)
+	fs.StringVar(&storeID, "store", "", "store ID")
+	fs.StringVar(&name, "name", "", "POS name")
+	fs.StringVar(&externalID, "external-id", "", "external POS ID used when generating QR codes")
+	fs.StringVar(&credentialsRef, "credentials-ref", "", "collector token holding the collector access token (optional)")
+	_ = fs.Parse(args)
+
+	if err := checkCredentialsRef(collectorTokens, credentialsRef); err != nil {
+		return err
+	}
+
+	pos, err := u.CreatePOS(ctx, storeID, name, externalID, credentialsRef)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("pos %s created (mercado pago id %s)\n", pos.ID, pos.ProviderPosID)
+	return nil
+}
+
+func updatePOS(ctx context.Context, u *usecases.UseCases, collectorTokens map[string]config.SecretSource, args []string) error {
+	var posID, name, credentialsRef string
+	fs := flag.NewFlagSet("pos update", flag.ExitOnError)
+	fs.StringVar(&posID, "id", "", "POS ID")
+	fs.StringVar(&name, "name", "", "new POS name")
+	fs.StringVar(&credentialsRef, "credentials-ref", "", "collector token holding the collector access token")
+	_ = fs.Parse(args)
+
+	if err := checkCredentialsRef(collectorTokens, credentialsRef); err != nil {
+		return err
+	}
+
+	pos, err := u.UpdatePOS(ctx, posID, name, credentialsRef)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("pos %s updated\n", pos.ID)
+	return nil
+}
+
+func listPOS(ctx context.Context, u *usecases.UseCases, collectorTokens map[string]config.SecretSource, args []string) error {
+	var externalStoreID, credentialsRef string
+	fs := flag.NewFlagSet("pos list", flag.ExitOnError)
+	fs.StringVar(&externalStoreID, "external-store-id", "", "filter by external store ID")
+	fs.StringVar(&credentialsRef, "credentials-ref", "", "collector token holding the collector access token (optional)")
+	_ = fs.Parse(args)
+
+	if err := checkCredentialsRef(collectorTokens, credentialsRef); err != nil {
+		return err
+	}
+
+	provisioned, local, err := u.ListPOS(ctx, externalStoreID, credentialsRef)
+	if err != nil {
+		return err
+	}
+
+	localIDs := make(map[string]string, len(local))
+	for _, pos := range local {
+		localIDs[pos.ProviderPosID] = pos.ID
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MP ID\tEXTERNAL ID\tSTORE\tNAME\tLOCAL ID")
+	for _, pos := range provisioned {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pos.ID, pos.ExternalID, pos.ExternalStoreID, pos.Name, orDash(localIDs[pos.ID]))
+	}
+	return w.Flush()
+}
+
+// checkCredentialsRef rejects refs the service could not resolve, which
+// would make every payment on the POS fail.
+func checkCredentialsRef(collectorTokens map[string]config.SecretSource, ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if _, found := collectorTokens[strings.ToLower(ref)]; !found {
+		return fmt.Errorf("credentials ref %q is not configured in app.providers.mercadopago.collector_tokens", ref)
+	}
+	return nil
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// generateServiceKey prints a new API key once, together with the entry to
+// add to the service credentials file; only the hash is ever stored.
+func generateServiceKey(args []string) error {
+	var service, keyID, scopes string
+	var expiresIn time.Duration
+	fs := flag.NewFlagSet("servicekey generate", flag.ExitOnError)
+	fs.StringVar(&service, "service", "", "calling service name (X-Service-Name)")
+	fs.StringVar(&keyID, "id", "", "key identifier (defaults to SERVICE-YYYYMMDD)")
+	fs.DurationVar(&expiresIn, "expires-in", 0, "key lifetime, e.g. 2160h; zero never expires")
+	fs.StringVar(&scopes, "scopes", "", "comma separated scopes")
+	_ = fs.Parse(args)
+
+	if service == "" {
+		return fmt.Errorf("-service is required")
+	}
+	if keyID == "" {
+		keyID = service + "-" + time.Now().UTC().Format("20060102")
+	}
+
+	key, err := credentials.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("key: %s\n\n", key)
+	fmt.Printf("  - service: %s\n    keys:\n      - id: %s\n        hash: %s\n", service, keyID, credentials.HashKey(key))
+	if expiresIn > 0 {
+		fmt.Printf("        expires_at: %s\n", time.Now().Add(expiresIn).UTC().Format(time.RFC3339))
+	}
+	if scopes != "" {
+		fmt.Printf("        scopes: [%s]\n", strings.Join(strings.Split(scopes, ","), ", "))
+	}
+	return nil
+}
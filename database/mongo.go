@@ -2,55 +2,55 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 type MongoDatabase struct {
 	client *mongo.Client
+	dbName string
 }
 
-func NewMongoDatabase() *MongoDatabase {
+func NewMongoDatabase(mongoURI, dbName string) *MongoDatabase {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	mongoURI := os.Getenv("MONGODB_URI")
-	if mongoURI == "" {
-		mongoURI = "mongodb://localhost:27017"
-	}
-
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI).SetMonitor(otelmongo.NewMonitor()))
 	if err != nil {
-		log.Fatal("Failed to connect to MongoDB:", err)
+		slog.Error("failed to connect to MongoDB", "error", err)
+		os.Exit(1)
 	}
 
 	// Test the connection
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal("Failed to ping MongoDB:", err)
+		slog.Error("failed to ping MongoDB", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("Connected to MongoDB successfully")
+	slog.Info("connected to MongoDB")
 
 	return &MongoDatabase{
 		client: client,
+		dbName: dbName,
 	}
 }
 
+// Close disconnects the client, waiting for in-use connections until ctx
+// is done.
+func (m *MongoDatabase) Close(ctx context.Context) error {
+	return m.client.Disconnect(ctx)
+}
+
 func (m *MongoDatabase) GetClient() *mongo.Client {
 	return m.client
 }
 
 func (m *MongoDatabase) GetDatabase() *mongo.Database {
-	dbName := os.Getenv("MONGODB_DATABASE")
-	if dbName == "" {
-		dbName = "golunch_payments"
-	}
-	return m.client.Database(dbName)
+	return m.client.Database(m.dbName)
 }
-
-
@@ -14,6 +14,8 @@ export OPERATION_SERVICE_URL="http://localhost:8083"
 # Mercado Pago Configuration (opcional para testes)
 export MERCADO_PAGO_ACCESS_TOKEN="test-token"
 export MERCADO_PAGO_SELLER_APP_USER_ID="test-seller"
+# sem MERCADO_PAGO_WEBHOOK_SECRET, aceita webhooks sem assinatura localmente
+export PAYMENT_APP_PROVIDERS_MERCADOPAGO_WEBHOOK_ALLOW_UNSIGNED="true"
 
 echo "Payment Service environment variables set:"
 echo "PORT: $PAYMENT_SERVICE_PORT"
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+// Check probes one dependency; a nil error means it is healthy.
+type Check func(ctx context.Context) error
+
+// Component is a named check reported by the readiness probe. A failing
+// critical component makes the service not ready; any other failure only
+// degrades the report, so an outage elsewhere does not pull every replica
+// out of rotation.
+type Component struct {
+	Name     string
+	Check    Check
+	Critical bool
+}
+
+// MongoCheck pings the primary.
+func MongoCheck(client *mongo.Client) Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
+// HTTPCheck calls url and fails on transport errors and 5xx answers.
+func HTTPCheck(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+
+		if res.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", res.StatusCode)
+		}
+		return nil
+	}
+}
+
+// ConfigCheck fails while any of the named settings is empty. Values are
+// read on every check, so a rotated secret is reported as it is now rather
+// than as it was at startup.
+func ConfigCheck(settings map[string]*secrets.Secret) Check {
+	return func(context.Context) error {
+		var missing []string
+		for name, setting := range settings {
+			if setting.Value() == "" {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		sort.Strings(missing)
+		return fmt.Errorf("missing configuration: %s", strings.Join(missing, ", "))
+	}
+}
+
+// BreakersCheck fails while any circuit breaker is not closed.
+func BreakersCheck(breakers *circuitbreaker.Registry) Check {
+	return func(context.Context) error {
+		var notClosed []string
+		for _, status := range breakers.Statuses() {
+			if status.State != circuitbreaker.StateClosed {
+				notClosed = append(notClosed, fmt.Sprintf("%s (%s)", status.Name, status.State))
+			}
+		}
+		if len(notClosed) == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("circuit breakers not closed: %s", strings.Join(notClosed, ", "))
+	}
+}
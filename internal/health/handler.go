@@ -0,0 +1,161 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+	StatusDraining = "draining"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+type LivenessResponseDTO struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponseDTO struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+type ComponentStatus struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Handler struct {
+	components   []Component
+	checkTimeout time.Duration
+	draining     atomic.Bool
+}
+
+// Option customizes the health handler.
+type Option func(*Handler)
+
+// WithComponents registers the checks run by the readiness probe.
+func WithComponents(components ...Component) Option {
+	return func(h *Handler) {
+		h.components = append(h.components, components...)
+	}
+}
+
+// WithCheckTimeout bounds each readiness check.
+func WithCheckTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		if timeout > 0 {
+			h.checkTimeout = timeout
+		}
+	}
+}
+
+func New(opts ...Option) *Handler {
+	h := &Handler{
+		checkTimeout: defaultCheckTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Live godoc
+// @Summary      Liveness probe
+// @Description  Answers as long as the process is serving requests; dependencies are not checked
+// @Tags         Health
+// @Produce      json
+// @Success      200 {object}  LivenessResponseDTO
+// @Router       /health/live [get]
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponseDTO{Status: StatusOK})
+}
+
+// Drain makes the readiness probe fail from now on, so the instance is
+// taken out of load balancing before it stops accepting connections.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready godoc
+// @Summary      Readiness probe
+// @Description  Checks MongoDB, Core reachability, required configuration and circuit breakers. Answers 503 when a critical component is down or the service is shutting down, and reports other failures as degraded
+// @Tags         Health
+// @Produce      json
+// @Success      200 {object}  ReadinessResponseDTO
+// @Failure      503 {object}  ReadinessResponseDTO
+// @Router       /health/ready [get]
+func (h *Handler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, ReadinessResponseDTO{Status: StatusDraining})
+		return
+	}
+
+	response := h.readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if response.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
+
+// readiness runs every check concurrently, each under its own timeout.
+func (h *Handler) readiness(ctx context.Context) ReadinessResponseDTO {
+	statuses := make([]ComponentStatus, len(h.components))
+
+	var wg sync.WaitGroup
+	for i, component := range h.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = h.check(ctx, component)
+		}()
+	}
+	wg.Wait()
+
+	response := ReadinessResponseDTO{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentStatus, len(h.components)),
+	}
+	for i, component := range h.components {
+		status := statuses[i]
+		response.Components[component.Name] = status
+		switch {
+		case status.Status == StatusOK:
+		case component.Critical:
+			response.Status = StatusDown
+		case response.Status == StatusOK:
+			response.Status = StatusDegraded
+		}
+	}
+	return response
+}
+
+func (h *Handler) check(ctx context.Context, component Component) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := component.Check(ctx)
+	status := ComponentStatus{
+		Status:    StatusOK,
+		Critical:  component.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
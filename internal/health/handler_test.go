@@ -0,0 +1,185 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+func healthy(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func TestHandler_Ready(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		components     []Component
+		expectedStatus int
+		expectedReport string
+	}{
+		{
+			name: "Given every component healthy, it should report ok",
+			components: []Component{
+				{Name: "mongodb", Check: healthy, Critical: true},
+				{Name: "core", Check: healthy},
+			},
+			expectedStatus: http.StatusOK,
+			expectedReport: StatusOK,
+		},
+		{
+			name: "Given a failing non-critical component, it should stay ready but degraded",
+			components: []Component{
+				{Name: "mongodb", Check: healthy, Critical: true},
+				{Name: "core", Check: failing},
+			},
+			expectedStatus: http.StatusOK,
+			expectedReport: StatusDegraded,
+		},
+		{
+			name: "Given a failing critical component, it should answer 503",
+			components: []Component{
+				{Name: "mongodb", Check: failing, Critical: true},
+				{Name: "core", Check: failing},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: StatusDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := New(WithComponents(tt.components...))
+			router := gin.New()
+			router.GET("/health/ready", handler.Ready)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response ReadinessResponseDTO
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedReport, response.Status)
+			require.Len(t, response.Components, len(tt.components))
+			for _, component := range tt.components {
+				status := response.Components[component.Name]
+				assert.Equal(t, component.Critical, status.Critical)
+				if component.Check(context.Background()) != nil {
+					assert.Equal(t, StatusDown, status.Status)
+					assert.Equal(t, "connection refused", status.Error)
+				} else {
+					assert.Equal(t, StatusOK, status.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Ready_Draining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := New(
+		WithComponents(Component{Name: "mongodb", Check: healthy, Critical: true}),
+	)
+	router := gin.New()
+	router.GET("/health/ready", handler.Ready)
+	ready := func() (int, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		var response ReadinessResponseDTO
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response.Status
+	}
+
+	code, status := ready()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, status)
+
+	handler.Drain()
+
+	code, status = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusDraining, status)
+}
+
+func TestHandler_Ready_TimesOutSlowChecks(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	handler := New(
+		WithCheckTimeout(10*time.Millisecond),
+		WithComponents(Component{Name: "core", Check: slow}),
+	)
+
+	response := handler.readiness(context.Background())
+
+	assert.Equal(t, StatusDegraded, response.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), response.Components["core"].Error)
+}
+
+func TestConfigCheck(t *testing.T) {
+	assert.NoError(t, ConfigCheck(map[string]*secrets.Secret{"MERCADO_PAGO_ACCESS_TOKEN": secrets.Static("token", "token")})(context.Background()))
+
+	err := ConfigCheck(map[string]*secrets.Secret{
+		"MERCADO_PAGO_EXTERNAL_POS_ID": secrets.Static("pos", ""),
+		"MERCADO_PAGO_ACCESS_TOKEN":    nil,
+		"CORE_SERVICE_URL":             secrets.Static("core", "http://core"),
+	})(context.Background())
+	assert.EqualError(t, err, "missing configuration: MERCADO_PAGO_ACCESS_TOKEN, MERCADO_PAGO_EXTERNAL_POS_ID")
+}
+
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectedErr bool
+	}{
+		{name: "Given a 200 answer, it should pass", status: http.StatusOK},
+		{name: "Given a 404 answer, it should still count as reachable", status: http.StatusNotFound},
+		{name: "Given a 503 answer, it should fail", status: http.StatusServiceUnavailable, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := HTTPCheck(server.Client(), server.URL+"/ping")(context.Background())
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBreakersCheck(t *testing.T) {
+	registry := circuitbreaker.NewRegistry(circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute})
+	registry.Get("mercadopago")
+	check := BreakersCheck(registry)
+
+	assert.NoError(t, check(context.Background()))
+
+	_ = registry.Get("core").Execute(func() error {
+		return &apperror.ProviderUnavailableError{Provider: "core", Msg: "status 503"}
+	})
+
+	assert.EqualError(t, check(context.Background()), "circuit breakers not closed: core (open)")
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/gateway"
 	"github.com/gin-gonic/gin"
@@ -10,35 +9,8 @@ import (
 
 // ServerlessAuthMiddleware validates JWT tokens via serverless auth
 // Following the exact same pattern as tc-golunch-api monolith
-func ServerlessAuthMiddleware(authGateway *gateway.ServerlessAuthGateway) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
-			return
-		}
-
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
-			return
-		}
-
-		tokenString := parts[1]
-
-		claims, err := authGateway.ValidateToken(tokenString)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			return
-		}
-
-		// Set context values exactly like monolith
-		c.Set("user_id", claims.UserID)
-		c.Set("user_type", claims.UserType)
-		c.Set("claims", claims)
-
-		c.Next()
-	}
+func ServerlessAuthMiddleware(authenticator gateway.Authenticator) gin.HandlerFunc {
+	return RequireAny(UserJWT(authenticator))
 }
 
 // ServerlessAdminOnly middleware to restrict access to admin users only
@@ -58,4 +30,4 @@ func ServerlessAdminOnly() gin.HandlerFunc {
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/credentials"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/mtls"
+)
+
+// Credential authenticates one kind of credential carried by a request. It
+// returns a missingCredential error when the request does not carry it, so
+// RequireAny can try the next one.
+type Credential func(c *gin.Context) (*entity.Principal, error)
+
+// missingCredential reports that a request carries no credential of a kind.
+type missingCredential string
+
+func (m missingCredential) Error() string {
+	return string(m)
+}
+
+// RequireAny authenticates the request with the first credential it
+// carries, in the given order, and stores the principal under
+// helper.PrincipalKey. A credential that is present but invalid rejects the
+// request even if a later one would have matched. Failures are answered
+// through helper.HandleError: 401 for rejected or missing credentials, 503
+// when the authenticator could not complete the check.
+//
+//	RequireAny(ServiceKey(auth), UserJWT(auth)) // service key OR user JWT
+func RequireAny(credentials ...Credential) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var missing error = missingCredential("Unauthorized: missing credentials")
+		if len(credentials) == 1 {
+			missing = nil
+		}
+
+		for _, credential := range credentials {
+			principal, err := credential(c)
+			var notCarried missingCredential
+			if errors.As(err, &notCarried) {
+				if missing == nil {
+					missing = err
+				}
+				continue
+			}
+			if err != nil {
+				helper.HandleError(c, err)
+				return
+			}
+
+			setPrincipal(c, principal)
+			c.Next()
+			return
+		}
+
+		helper.HandleError(c, &apperror.UnauthorizedError{Msg: missing.Error()})
+	}
+}
+
+// rejected reports an invalid credential.
+func rejected(msg string) error {
+	return &apperror.UnauthorizedError{Msg: msg}
+}
+
+// authenticatorError tells a credential the authenticator rejected from a
+// check it could not complete, e.g. the auth Lambda or the JWKS endpoint
+// being down; only the former is the caller's fault.
+func authenticatorError(err error, msg string) error {
+	if errors.Is(err, gateway.ErrInvalidToken) ||
+		errors.Is(err, credentials.ErrInvalidKey) ||
+		errors.Is(err, credentials.ErrUnknownService) {
+		return rejected(msg)
+	}
+	return &apperror.UnavailableError{Msg: "authentication unavailable: " + err.Error()}
+}
+
+// setPrincipal stores the principal, along with the keys earlier handlers
+// read directly.
+func setPrincipal(c *gin.Context, principal *entity.Principal) {
+	helper.SetPrincipal(c, principal)
+	switch {
+	case principal.Service != nil:
+		c.Set("authenticated_service", principal.Service.ServiceName)
+		c.Set("service_claims", principal.Service)
+	case principal.User != nil:
+		c.Set("user_id", principal.User.UserID)
+		c.Set("user_type", principal.User.UserType)
+		c.Set("claims", principal.User)
+	}
+}
+
+// ServiceKey authenticates X-Service-Name / X-Service-Key, verifying the
+// request signature when configured.
+func ServiceKey(authenticator gateway.Authenticator, opts ...ServiceAuthOption) Credential {
+	var options serviceAuthOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(c *gin.Context) (*entity.Principal, error) {
+		serviceName := c.GetHeader("X-Service-Name")
+		serviceKey := c.GetHeader("X-Service-Key")
+		if serviceName == "" && serviceKey == "" {
+			return nil, missingCredential("Unauthorized: Invalid service credentials")
+		}
+
+		if serviceName == "" || serviceKey == "" {
+			return nil, rejected("Unauthorized: Invalid service credentials")
+		}
+
+		claims, err := authenticator.ValidateServiceToken(c.Request.Context(), serviceKey, serviceName)
+		if err != nil {
+			return nil, authenticatorError(err, "Unauthorized: Invalid service credentials")
+		}
+
+		if err := options.verifySignature(c, serviceName); err != nil {
+			slog.Warn("service request signature rejected", "service", serviceName, "error", err)
+			return nil, rejected("Unauthorized: Invalid request signature")
+		}
+		return entity.NewServicePrincipal(claims), nil
+	}
+}
+
+// ClientCert authenticates a service by the verified mTLS client certificate
+// of the connection. A verified certificate that maps to no service is
+// rejected rather than ignored.
+func ClientCert(identities *mtls.Identities) Credential {
+	return func(c *gin.Context) (*entity.Principal, error) {
+		claims, present := identities.Resolve(c.Request.TLS)
+		if !present {
+			return nil, missingCredential("Unauthorized: client certificate missing")
+		}
+		if claims == nil {
+			return nil, rejected("Unauthorized: Unknown client certificate")
+		}
+		return entity.NewServicePrincipal(claims), nil
+	}
+}
+
+// UserJWT authenticates a Bearer token in the Authorization header.
+func UserJWT(authenticator gateway.Authenticator) Credential {
+	return func(c *gin.Context) (*entity.Principal, error) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			return nil, missingCredential("Authorization header missing")
+		}
+
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" || parts[1] == "" {
+			return nil, rejected("Invalid Authorization header format")
+		}
+
+		claims, err := authenticator.ValidateToken(c.Request.Context(), parts[1])
+		if err != nil {
+			return nil, authenticatorError(err, "Invalid token")
+		}
+		return entity.NewUserPrincipal(claims), nil
+	}
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/mtls"
+)
+
+func TestRequireAny_ServiceKeyOrUserJWT(t *testing.T) {
+	authenticator := newStubAuthenticator(t)
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name              string
+		serviceName       string
+		serviceKey        string
+		authHeader        string
+		expectedStatus    int
+		expectedBody      string
+		expectedKind      entity.PrincipalKind
+		expectedPrincipal string
+	}{
+		{
+			name:              "Given a valid service key, it should authenticate the service",
+			serviceName:       "core-service",
+			serviceKey:        "test-core-api-key",
+			expectedStatus:    http.StatusOK,
+			expectedKind:      entity.PrincipalService,
+			expectedPrincipal: "core-service",
+		},
+		{
+			name:              "Given a valid user JWT, it should authenticate the user",
+			authHeader:        "Bearer valid-user-token",
+			expectedStatus:    http.StatusOK,
+			expectedKind:      entity.PrincipalUser,
+			expectedPrincipal: "user-1",
+		},
+		{
+			name:           "Given an invalid user JWT, it should be unauthorized",
+			authHeader:     "Bearer forged-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid token",
+		},
+		{
+			name:           "Given a malformed Authorization header, it should be unauthorized",
+			authHeader:     "Basic dXNlcjpwYXNz",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid Authorization header format",
+		},
+		{
+			name:           "Given an invalid service key and a valid JWT, it should not fall through to the JWT",
+			serviceName:    "core-service",
+			serviceKey:     "wrong-api-key",
+			authHeader:     "Bearer valid-user-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized: Invalid service credentials",
+		},
+		{
+			name:           "Given an empty bearer token, it should be unauthorized",
+			authHeader:     "Bearer ",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid Authorization header format",
+		},
+		{
+			name:           "Given the authenticator is down, it should be unavailable rather than unauthorized",
+			authHeader:     "Bearer auth-down",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `"code":"service_unavailable"`,
+		},
+		{
+			name:           "Given no credentials, it should be unauthorized",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `"code":"unauthorized"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal *entity.Principal
+			router := gin.New()
+			router.GET("/payments/:id", RequireAny(ServiceKey(authenticator), UserJWT(authenticator)), func(c *gin.Context) {
+				principal, _ = helper.Principal(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/payments/1", nil)
+			if tt.serviceName != "" {
+				req.Header.Set("X-Service-Name", tt.serviceName)
+				req.Header.Set("X-Service-Key", tt.serviceKey)
+			}
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedKind, principal.Kind)
+				assert.Equal(t, tt.expectedPrincipal, principal.ID())
+			}
+		})
+	}
+}
+
+func TestServerlessAuthMiddleware_MissingHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", ServerlessAuthMiddleware(newStubAuthenticator(t)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Authorization header missing")
+}
+
+func TestClientCert(t *testing.T) {
+	identities := mtls.NewIdentities([]mtls.Identity{{Subject: "core-service", Service: "core-service", Scopes: []string{"payments:read"}}})
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		subject        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Given a mapped certificate subject, it should authenticate the service",
+			subject:        "core-service",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "core-service",
+		},
+		{
+			name:           "Given an unmapped certificate subject, it should be unauthorized",
+			subject:        "unknown-service",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized: Unknown client certificate",
+		},
+		{
+			name:           "Given neither a client certificate nor a service key, it should be unauthorized",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unauthorized: missing credentials",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", RequireAny(ClientCert(identities), ServiceKey(newStubAuthenticator(t))), func(c *gin.Context) {
+				principal, _ := helper.Principal(c)
+				c.String(http.StatusOK, principal.ID())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.subject != "" {
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: tt.subject}}
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+			}
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/authz"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+)
+
+// RequirePermissions authorizes the principal set by RequireAny (or bare
+// claims set under "claims") against the policy. Requests missing any permission get 403 naming it;
+// the resolved grants are left on the context under authz.ContextKey.
+func RequirePermissions(policy *authz.Policy, permissions ...authz.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := helper.Principal(c)
+		if !ok {
+			claims, _ := c.Get("claims")
+			customClaims, isClaims := claims.(*entity.CustomClaims)
+			if !isClaims || customClaims == nil {
+				helper.HandleError(c, &apperror.UnauthorizedError{Msg: "Claims not found in context"})
+				return
+			}
+			principal = entity.NewUserPrincipal(customClaims)
+		}
+
+		grants := policy.PrincipalGrants(principal)
+		if missing := grants.Missing(permissions...); len(missing) > 0 {
+			names := make([]string, len(missing))
+			for i, permission := range missing {
+				names[i] = string(permission)
+			}
+			helper.HandleError(c, &apperror.ForbiddenError{Msg: "missing permission: " + strings.Join(names, ", ")})
+			return
+		}
+
+		c.Set(authz.ContextKey, grants)
+		c.Next()
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/authz"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+)
+
+func TestRequirePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := authz.NewPolicy(nil)
+
+	tests := []struct {
+		name            string
+		claims          *entity.CustomClaims
+		principal       *entity.Principal
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "Given a cashier, it should pass",
+			claims:         &entity.CustomClaims{UserID: "u-1", UserType: "cashier"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Given a customer, it should name the missing permission",
+			claims:          &entity.CustomClaims{UserID: "u-2", UserType: "customer"},
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "missing permission: payments:cash_confirm",
+		},
+		{
+			name:           "Given a service whose key has the scope, it should pass",
+			principal:      entity.NewServicePrincipal(&entity.ServiceClaims{ServiceName: "core-service", Scopes: []string{"payments:cash_confirm"}}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "Given a service without the scope, it should name the missing permission",
+			principal:       entity.NewServicePrincipal(&entity.ServiceClaims{ServiceName: "core-service"}),
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "missing permission: payments:cash_confirm",
+		},
+		{
+			name:            "Given no claims, it should be unauthorized",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Claims not found in context",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set("claims", tt.claims)
+				}
+				if tt.principal != nil {
+					helper.SetPrincipal(c, tt.principal)
+				}
+			})
+			router.POST("/", RequirePermissions(policy, authz.PermissionPaymentsCashConfirm), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/", nil))
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedMessage != "" {
+				var body apperror.ErrorDTO
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				assert.Equal(t, tt.expectedMessage, body.MessageError)
+			}
+		})
+	}
+}
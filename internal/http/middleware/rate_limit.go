@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/ratelimit"
+)
+
+// RateLimiter takes a token for a key; *ratelimit.Limiter implements it.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit ratelimit.Limit) ratelimit.Decision
+}
+
+// RateLimit gives every caller of the route group its own token bucket,
+// keyed by the authenticated service or user, or by client IP on public
+// routes. Mount it after the auth middleware so the principal is known.
+// Rejected requests get 429 with Retry-After.
+func RateLimit(limiter RateLimiter, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		decision := limiter.Allow(c.Request.Context(), group+":"+rateLimitKey(c), limit)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		if !decision.Allowed {
+			helper.HandleError(c, &apperror.RateLimitedError{
+				Msg:        "rate limit exceeded for " + group,
+				RetryAfter: decision.RetryAfter,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitKey(c *gin.Context) string {
+	if principal, ok := helper.Principal(c); ok {
+		return string(principal.Kind) + ":" + principal.ID()
+	}
+	return "ip:" + c.ClientIP()
+}
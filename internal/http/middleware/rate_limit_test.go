@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryBackend())
+	limit := ratelimit.Limit{RequestsPerMinute: 1, Burst: 2}
+
+	router := gin.New()
+	router.POST("/payments", func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			helper.SetPrincipal(c, entity.NewUserPrincipal(&entity.CustomClaims{UserID: user}))
+		}
+	}, RateLimit(limiter, "payments_create", limit), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	send := func(remoteAddr, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.RemoteAddr = remoteAddr
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusCreated, send("10.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusCreated, send("10.0.0.1:1234", "").Code)
+
+	limited := send("10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "60", limited.Header().Get("Retry-After"))
+	assert.Contains(t, limited.Body.String(), "rate limit exceeded for payments_create")
+
+	assert.Equal(t, http.StatusCreated, send("10.0.0.2:1234", "").Code, "another IP has its own budget")
+	assert.Equal(t, http.StatusCreated, send("10.0.0.1:1234", "user-1").Code, "authenticated users are keyed by user, not IP")
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		inbound  string
+		expected string
+	}{
+		{
+			name:     "Given a caller request ID, it should propagate it",
+			inbound:  "core-7f3a",
+			expected: "core-7f3a",
+		},
+		{
+			name:    "Given no request ID, it should generate one",
+			inbound: "",
+		},
+		{
+			name:    "Given a malformed request ID, it should replace it",
+			inbound: "bad id\nwith newline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromGin, fromContext string
+			router := gin.New()
+			router.Use(RequestID())
+			router.GET("/", func(c *gin.Context) {
+				fromGin = helper.RequestID(c)
+				fromContext = logger.RequestID(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.inbound != "" {
+				req.Header.Set(helper.RequestIDHeader, tt.inbound)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			id := resp.Header().Get(helper.RequestIDHeader)
+			assert.NotEmpty(t, id)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, id)
+			} else {
+				assert.NotEqual(t, tt.inbound, id)
+			}
+			assert.Equal(t, id, fromGin)
+			assert.Equal(t, id, fromContext)
+		})
+	}
+}
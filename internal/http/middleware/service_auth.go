@@ -1,79 +1,69 @@
 package middleware
 
 import (
-	"net/http"
-	"os"
-	"strings"
+	"bytes"
+	"errors"
+	"io"
 
 	"github.com/gin-gonic/gin"
-)
 
-// ServiceAuthMiddleware validates service-to-service authentication
-func ServiceAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Skip authentication for health checks and public endpoints
-		if c.Request.URL.Path == "/ping" || c.Request.URL.Path == "/health" {
-			c.Next()
-			return
-		}
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/mtls"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/signing"
+)
 
-		// Method 1: API Key Authentication (preferred for service-to-service)
-		serviceName := c.GetHeader("X-Service-Name")
-		serviceKey := c.GetHeader("X-Service-Key")
+// maxSignedBodyBytes bounds how much of a request body is buffered to verify
+// its signature.
+const maxSignedBodyBytes = 1 << 20
 
-		if serviceName != "" && serviceKey != "" {
-			if validateServiceAPIKey(serviceName, serviceKey) {
-				c.Set("authenticated_service", serviceName)
-				c.Next()
-				return
-			}
-		}
+// ServiceAuthOption customizes ServiceKey.
+type ServiceAuthOption func(*serviceAuthOptions)
 
-		// Method 2: JWT Token Authentication (for user requests)
-		authHeader := c.GetHeader("Authorization")
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			// Let the existing auth middleware handle JWT tokens
-			c.Next()
-			return
-		}
-
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid service credentials"})
-	}
+type serviceAuthOptions struct {
+	verifier          *signing.Verifier
+	requireSignatures bool
+	identities        *mtls.Identities
 }
 
-// validateServiceAPIKey validates API key for service authentication
-func validateServiceAPIKey(serviceName, apiKey string) bool {
-	var expectedKey string
-
-	switch serviceName {
-	case "core-service":
-		expectedKey = os.Getenv("CORE_SERVICE_API_KEY")
-	case "payment-service":
-		expectedKey = os.Getenv("PAYMENT_SERVICE_API_KEY")
-	case "operation-service":
-		expectedKey = os.Getenv("OPERATION_SERVICE_API_KEY")
-	default:
-		return false
+// WithSignatureVerification checks the HMAC signature of service requests.
+// Unsigned requests are still accepted unless required is set, so callers
+// can be migrated one at a time.
+func WithSignatureVerification(verifier *signing.Verifier, required bool) ServiceAuthOption {
+	return func(o *serviceAuthOptions) {
+		o.verifier = verifier
+		o.requireSignatures = required
 	}
+}
 
-	if expectedKey == "" {
-		return false
+// WithClientCertificates also accepts services identified by a verified mTLS
+// client certificate, checked before the API key headers.
+func WithClientCertificates(identities *mtls.Identities) ServiceAuthOption {
+	return func(o *serviceAuthOptions) {
+		o.identities = identities
 	}
-
-	// Use constant-time comparison to prevent timing attacks
-	return constantTimeEquals(apiKey, expectedKey)
 }
 
-// constantTimeEquals performs constant-time string comparison
-func constantTimeEquals(a, b string) bool {
-	if len(a) != len(b) {
-		return false
+// verifySignature buffers the body, checks its signature and puts it back
+// for the handler.
+func (o serviceAuthOptions) verifySignature(c *gin.Context, serviceName string) error {
+	if o.verifier == nil {
+		return nil
+	}
+	if c.GetHeader(signing.SignatureHeader) == "" && !o.requireSignatures {
+		return nil
 	}
 
-	result := 0
-	for i := 0; i < len(a); i++ {
-		result |= int(a[i]) ^ int(b[i])
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodyBytes+1))
+		if err != nil {
+			return err
+		}
+		if len(body) > maxSignedBodyBytes {
+			return errors.New("request body too large to verify")
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
-	return result == 0
+	return o.verifier.Verify(c.Request, serviceName, body)
 }
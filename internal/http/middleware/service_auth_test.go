@@ -1,19 +1,68 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http/httptest"
-	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/credentials"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/signing"
 )
 
-func TestServiceAuthMiddleware(t *testing.T) {
-	// Set test environment variables
-	os.Setenv("CORE_SERVICE_API_KEY", "test-core-api-key")
-	os.Setenv("PAYMENT_SERVICE_API_KEY", "test-payment-api-key")
-	os.Setenv("OPERATION_SERVICE_API_KEY", "test-production-api-key")
+// stubAuthenticator checks service keys against a local credential store
+// and falls back to accepting a single remote service key.
+type stubAuthenticator struct {
+	store *credentials.Store
+}
+
+type staticSource []credentials.ServiceCredential
+
+func (s staticSource) Load(ctx context.Context) ([]credentials.ServiceCredential, error) {
+	return s, nil
+}
+
+func newStubAuthenticator(t *testing.T) stubAuthenticator {
+	store := credentials.NewStore(staticSource{
+		{Service: "core-service", Keys: []credentials.ServiceKey{{ID: "core-1", Hash: credentials.HashKey("test-core-api-key")}}},
+		{Service: "payment-service", Keys: []credentials.ServiceKey{{ID: "payment-1", Hash: credentials.HashKey("test-payment-api-key")}}},
+	})
+	if err := store.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return stubAuthenticator{store: store}
+}
+
+func (stubAuthenticator) ValidateToken(ctx context.Context, token string) (*entity.CustomClaims, error) {
+	switch token {
+	case "valid-user-token":
+		return &entity.CustomClaims{UserID: "user-1", UserType: "customer"}, nil
+	case "auth-down":
+		return nil, errors.New("failed to call serverless auth: connection refused")
+	}
+	return nil, gateway.ErrInvalidToken
+}
+
+func (s stubAuthenticator) ValidateServiceToken(ctx context.Context, apiKey, serviceName string) (*entity.ServiceClaims, error) {
+	switch {
+	case serviceName == "remote-service" && apiKey == "remote-key":
+		return &entity.ServiceClaims{ServiceName: serviceName}, nil
+	case serviceName == "remote-service":
+		return nil, errors.New("service auth error: status 502")
+	}
+	return s.store.Verify(serviceName, apiKey)
+}
+
+func TestServiceKey(t *testing.T) {
+	authenticator := newStubAuthenticator(t)
 
 	gin.SetMode(gin.TestMode)
 
@@ -27,14 +76,6 @@ func TestServiceAuthMiddleware(t *testing.T) {
 		expectedStatus int
 		expectedBody   string
 	}{
-		{
-			name:           "Health check endpoint bypasses auth",
-			path:           "/ping",
-			method:         "GET",
-			serviceName:    "",
-			serviceKey:     "",
-			expectedStatus: 200,
-		},
 		{
 			name:           "Valid service credentials",
 			path:           "/api/test",
@@ -51,6 +92,23 @@ func TestServiceAuthMiddleware(t *testing.T) {
 			serviceKey:     "test-payment-api-key",
 			expectedStatus: 200,
 		},
+		{
+			name:           "Service key accepted by the authenticator",
+			path:           "/api/test",
+			method:         "GET",
+			serviceName:    "remote-service",
+			serviceKey:     "remote-key",
+			expectedStatus: 200,
+		},
+		{
+			name:           "Authenticator unavailable",
+			path:           "/api/test",
+			method:         "GET",
+			serviceName:    "remote-service",
+			serviceKey:     "other-key",
+			expectedStatus: 503,
+			expectedBody:   "authentication unavailable",
+		},
 		{
 			name:           "Invalid service name",
 			path:           "/api/test",
@@ -88,11 +146,12 @@ func TestServiceAuthMiddleware(t *testing.T) {
 			expectedBody:   "Unauthorized: Invalid service credentials",
 		},
 		{
-			name:           "JWT token should pass through",
+			name:           "Bearer token alone is not a service credential",
 			path:           "/api/test",
 			method:         "GET",
-			authHeader:     "Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.test",
-			expectedStatus: 200, // Should pass through to next middleware
+			authHeader:     "Bearer valid-user-token",
+			expectedStatus: 401,
+			expectedBody:   "Unauthorized: Invalid service credentials",
 		},
 	}
 
@@ -100,7 +159,7 @@ func TestServiceAuthMiddleware(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup router with middleware
 			router := gin.New()
-			router.Use(ServiceAuthMiddleware())
+			router.Use(RequireAny(ServiceKey(authenticator)))
 
 			// Add test route
 			router.Any("/*path", func(c *gin.Context) {
@@ -129,7 +188,7 @@ func TestServiceAuthMiddleware(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, resp.Code, "Status code mismatch")
 
 			// Assert error message for auth failures
-			if tt.expectedBody != "" && resp.Code == 401 {
+			if tt.expectedBody != "" {
 				assert.Contains(t, resp.Body.String(), tt.expectedBody)
 			}
 
@@ -140,117 +199,73 @@ func TestServiceAuthMiddleware(t *testing.T) {
 			}
 		})
 	}
-
-	// Cleanup
-	os.Unsetenv("CORE_SERVICE_API_KEY")
-	os.Unsetenv("PAYMENT_SERVICE_API_KEY")
-	os.Unsetenv("OPERATION_SERVICE_API_KEY")
 }
 
-func TestValidateServiceAPIKey(t *testing.T) {
-	// Set test environment variables
-	os.Setenv("CORE_SERVICE_API_KEY", "test-core-key")
-	os.Setenv("PAYMENT_SERVICE_API_KEY", "test-payment-key")
+func TestServiceKey_SignatureVerification(t *testing.T) {
+	authenticator := newStubAuthenticator(t)
+	secrets := signing.Secrets{"core-service": []byte("core-signing-secret")}
+	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
-		name        string
-		serviceName string
-		apiKey      string
-		expected    bool
+		name           string
+		required       bool
+		sign           bool
+		sentBody       string
+		expectedStatus int
+		expectedBody   string
 	}{
 		{
-			name:        "Valid core service key",
-			serviceName: "core-service",
-			apiKey:      "test-core-key",
-			expected:    true,
-		},
-		{
-			name:        "Valid payment service key",
-			serviceName: "payment-service",
-			apiKey:      "test-payment-key",
-			expected:    true,
-		},
-		{
-			name:        "Invalid service name",
-			serviceName: "unknown-service",
-			apiKey:      "test-core-key",
-			expected:    false,
+			name:           "Given a correctly signed request, it should reach the handler with the body intact",
+			sign:           true,
+			sentBody:       `{"order_id":"1"}`,
+			expectedStatus: 200,
+			expectedBody:   `{"order_id":"1"}`,
 		},
 		{
-			name:        "Invalid API key",
-			serviceName: "core-service",
-			apiKey:      "wrong-key",
-			expected:    false,
+			name:           "Given a body changed after signing, it should reject the request",
+			sign:           true,
+			sentBody:       `{"order_id":"2"}`,
+			expectedStatus: 401,
+			expectedBody:   "Unauthorized: Invalid request signature",
 		},
 		{
-			name:        "Empty service name",
-			serviceName: "",
-			apiKey:      "test-core-key",
-			expected:    false,
+			name:           "Given an unsigned request and optional signing, it should accept the request",
+			sentBody:       `{"order_id":"1"}`,
+			expectedStatus: 200,
+			expectedBody:   `{"order_id":"1"}`,
 		},
 		{
-			name:        "Empty API key",
-			serviceName: "core-service",
-			apiKey:      "",
-			expected:    false,
+			name:           "Given an unsigned request and required signing, it should reject the request",
+			required:       true,
+			sentBody:       `{"order_id":"1"}`,
+			expectedStatus: 401,
+			expectedBody:   "Unauthorized: Invalid request signature",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validateServiceAPIKey(tt.serviceName, tt.apiKey)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
+			verifier := signing.NewVerifier(secrets.Lookup, time.Minute, signing.NewMemoryNonceStore())
+			router := gin.New()
+			router.Use(RequireAny(ServiceKey(authenticator, WithSignatureVerification(verifier, tt.required))))
+			router.POST("/api/orders", func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				c.String(200, string(body))
+			})
 
-	// Cleanup
-	os.Unsetenv("CORE_SERVICE_API_KEY")
-	os.Unsetenv("PAYMENT_SERVICE_API_KEY")
-}
+			req := httptest.NewRequest("POST", "/api/orders", strings.NewReader(tt.sentBody))
+			req.Header.Set("X-Service-Name", "core-service")
+			req.Header.Set("X-Service-Key", "test-core-api-key")
+			if tt.sign {
+				err := signing.NewSigner([]byte("core-signing-secret")).Sign(req, []byte(`{"order_id":"1"}`))
+				assert.NoError(t, err)
+			}
 
-func TestConstantTimeEquals(t *testing.T) {
-	tests := []struct {
-		name     string
-		a        string
-		b        string
-		expected bool
-	}{
-		{
-			name:     "Equal strings",
-			a:        "test-key-123",
-			b:        "test-key-123",
-			expected: true,
-		},
-		{
-			name:     "Different strings same length",
-			a:        "test-key-123",
-			b:        "test-key-456",
-			expected: false,
-		},
-		{
-			name:     "Different lengths",
-			a:        "short",
-			b:        "much-longer-string",
-			expected: false,
-		},
-		{
-			name:     "Empty strings",
-			a:        "",
-			b:        "",
-			expected: true,
-		},
-		{
-			name:     "One empty string",
-			a:        "test",
-			b:        "",
-			expected: false,
-		},
-	}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := constantTimeEquals(tt.a, tt.b)
-			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
 		})
 	}
 }
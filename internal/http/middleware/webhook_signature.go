@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+const (
+	webhookSignatureHeader = "x-signature"
+	webhookRequestIDHeader = "x-request-id"
+
+	// DefaultWebhookTolerance is how far a notification timestamp may be from
+	// now before it is rejected as a replay.
+	DefaultWebhookTolerance = 5 * time.Minute
+)
+
+// WebhookOption customizes WebhookSignature.
+type WebhookOption func(*webhookOptions)
+
+type webhookOptions struct {
+	tolerance     time.Duration
+	allowUnsigned bool
+}
+
+// WithWebhookTolerance sets how far the signed timestamp may drift from now.
+func WithWebhookTolerance(tolerance time.Duration) WebhookOption {
+	return func(o *webhookOptions) {
+		o.tolerance = tolerance
+	}
+}
+
+// WithUnsignedWebhooks accepts notifications without x-signature, and any
+// notification while no secret is configured. Meant for local development.
+func WithUnsignedWebhooks() WebhookOption {
+	return func(o *webhookOptions) {
+		o.allowUnsigned = true
+	}
+}
+
+// WebhookSignature verifies the x-signature Mercado Pago sends with webhook
+// notifications, an HMAC-SHA256 of the notified data.id, x-request-id and
+// timestamp keyed by the webhook secret, and rejects timestamps outside the
+// tolerance so a captured notification cannot be replayed later. The secret
+// is read on every request, so a rotated one applies immediately.
+func WebhookSignature(secret *secrets.Secret, opts ...WebhookOption) gin.HandlerFunc {
+	options := webhookOptions{tolerance: DefaultWebhookTolerance}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(c *gin.Context) {
+		ts, signature := parseWebhookSignature(c.GetHeader(webhookSignatureHeader))
+		key := secret.Value()
+		unsigned := c.GetHeader(webhookSignatureHeader) == ""
+		if options.allowUnsigned && (key == "" || unsigned) {
+			c.Next()
+			return
+		}
+		if key == "" {
+			helper.HandleError(c, &apperror.UnauthorizedError{Msg: "webhook secret is not configured"})
+			return
+		}
+		if ts == "" || signature == "" {
+			helper.HandleError(c, &apperror.UnauthorizedError{Msg: "missing webhook signature"})
+			return
+		}
+		if !withinTolerance(ts, time.Now(), options.tolerance) {
+			slog.WarnContext(c.Request.Context(), "webhook timestamp outside tolerance", "ts", ts, "request_id", c.GetHeader(webhookRequestIDHeader))
+			helper.HandleError(c, &apperror.UnauthorizedError{Msg: "webhook timestamp outside tolerance"})
+			return
+		}
+
+		expected := WebhookManifestSignature(key, c.Query("data.id"), c.GetHeader(webhookRequestIDHeader), ts)
+		if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+			slog.WarnContext(c.Request.Context(), "webhook signature mismatch", "request_id", c.GetHeader(webhookRequestIDHeader))
+			helper.HandleError(c, &apperror.UnauthorizedError{Msg: "invalid webhook signature"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// WebhookManifestSignature signs the manifest Mercado Pago builds for a
+// notification, "id:<data.id>;request-id:<x-request-id>;ts:<ts>;", leaving
+// out the parts the notification does not carry.
+func WebhookManifestSignature(secret, dataID, requestID, ts string) string {
+	var manifest strings.Builder
+	if dataID != "" {
+		manifest.WriteString("id:" + strings.ToLower(dataID) + ";")
+	}
+	if requestID != "" {
+		manifest.WriteString("request-id:" + requestID + ";")
+	}
+	manifest.WriteString("ts:" + ts + ";")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(manifest.String()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// withinTolerance reports whether ts, in Unix seconds or milliseconds, is at
+// most tolerance away from now.
+func withinTolerance(ts string, now time.Time, tolerance time.Duration) bool {
+	value, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	signedAt := time.Unix(value, 0)
+	if value > 1e12 {
+		signedAt = time.UnixMilli(value)
+	}
+
+	drift := now.Sub(signedAt)
+	return drift <= tolerance && drift >= -tolerance
+}
+
+// parseWebhookSignature splits "ts=1704908010,v1=<hex>".
+func parseWebhookSignature(header string) (ts, signature string) {
+	for _, part := range strings.Split(header, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch name {
+		case "ts":
+			ts = value
+		case "v1":
+			signature = value
+		}
+	}
+	return ts, signature
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const target = "/webhook/payment/check?data.id=ORD01JQ&type=order"
+	sign := func(ts int64) string {
+		value := strconv.FormatInt(ts, 10)
+		return "ts=" + value + ",v1=" + WebhookManifestSignature("webhook-secret", "ORD01JQ", "req-1", value)
+	}
+	valid := sign(time.Now().Unix())
+	validMillis := sign(time.Now().UnixMilli())
+
+	tests := []struct {
+		name           string
+		secret         string
+		allowUnsigned  bool
+		signature      string
+		requestID      string
+		expectedStatus int
+	}{
+		{name: "Given no secret and unsigned webhooks not allowed, it should reject the notification", expectedStatus: http.StatusUnauthorized},
+		{name: "Given no secret and unsigned webhooks allowed, it should accept the notification", allowUnsigned: true, expectedStatus: http.StatusOK},
+		{name: "Given a secret and unsigned webhooks allowed, it should accept a notification without signature", secret: "webhook-secret", allowUnsigned: true, requestID: "req-1", expectedStatus: http.StatusOK},
+		{name: "Given a secret and unsigned webhooks allowed, it should still reject a bad signature", secret: "rotated-secret", allowUnsigned: true, signature: valid, requestID: "req-1", expectedStatus: http.StatusUnauthorized},
+		{name: "Given a valid signature, it should accept the notification", secret: "webhook-secret", signature: valid, requestID: "req-1", expectedStatus: http.StatusOK},
+		{name: "Given a valid signature with a millisecond timestamp, it should accept the notification", secret: "webhook-secret", signature: validMillis, requestID: "req-1", expectedStatus: http.StatusOK},
+		{name: "Given no signature, it should reject the notification", secret: "webhook-secret", requestID: "req-1", expectedStatus: http.StatusUnauthorized},
+		{name: "Given a different request ID, it should reject the notification", secret: "webhook-secret", signature: valid, requestID: "req-2", expectedStatus: http.StatusUnauthorized},
+		{name: "Given a signature made with another secret, it should reject the notification", secret: "rotated-secret", signature: valid, requestID: "req-1", expectedStatus: http.StatusUnauthorized},
+		{name: "Given a validly signed but old notification, it should reject it as a replay", secret: "webhook-secret", signature: sign(time.Now().Add(-time.Hour).Unix()), requestID: "req-1", expectedStatus: http.StatusUnauthorized},
+		{name: "Given a timestamp too far in the future, it should reject the notification", secret: "webhook-secret", signature: sign(time.Now().Add(time.Hour).Unix()), requestID: "req-1", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []WebhookOption
+			if tt.allowUnsigned {
+				opts = append(opts, WithUnsignedWebhooks())
+			}
+
+			r := gin.New()
+			r.POST("/webhook/payment/check", WebhookSignature(secrets.Static("webhook secret", tt.secret), opts...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, target, nil)
+			if tt.signature != "" {
+				req.Header.Set("x-signature", tt.signature)
+			}
+			req.Header.Set("x-request-id", tt.requestID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestWebhookSignature_RotatedSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	path := filepath.Join(t.TempDir(), "webhook-secret")
+	require.NoError(t, os.WriteFile(path, []byte("secret-1"), 0o600))
+	secret, err := secrets.FromFile("webhook secret", path)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.POST("/webhook/payment/check", WebhookSignature(secret), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/payment/check?data.id=123", nil)
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("x-signature", "ts="+ts+",v1="+WebhookManifestSignature(key, "123", "req-1", ts))
+		req.Header.Set("x-request-id", "req-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("secret-1"))
+
+	require.NoError(t, os.WriteFile(path, []byte("secret-2"), 0o600))
+	_, err = secret.Reload()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, send("secret-1"))
+	assert.Equal(t, http.StatusOK, send("secret-2"))
+}
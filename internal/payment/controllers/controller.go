@@ -18,10 +18,10 @@ func Build(paymentUseCase *usecases.UseCases) *Controller {
 	}
 }
 
-func (c *Controller) CreateByOrderID(ctx context.Context, orderID string) (dto.PaymentResponseDTO, error) {
+func (c *Controller) CreateByOrderID(ctx context.Context, orderID, posID, customerID string) (dto.PaymentResponseDTO, error) {
 	presenter := presenter.Build()
 
-	payment, err := c.paymentUseCase.CreateByOrderID(ctx, orderID)
+	payment, err := c.paymentUseCase.CreateByOrderID(ctx, orderID, posID, customerID)
 	if err != nil {
 		return dto.PaymentResponseDTO{}, err
 	}
@@ -29,6 +29,61 @@ func (c *Controller) CreateByOrderID(ctx context.Context, orderID string) (dto.P
 	return presenter.FromEntityToResponseDTO(payment), nil
 }
 
-func (c *Controller) CheckPayment(ctx context.Context, requestUrl string) (interface{}, error) {
-	return c.paymentUseCase.CheckPayment(ctx, requestUrl)
+func (c *Controller) CreateCashByOrderID(ctx context.Context, orderID, customerID string) (dto.PaymentResponseDTO, error) {
+	presenter := presenter.Build()
+
+	payment, err := c.paymentUseCase.CreateCashByOrderID(ctx, orderID, customerID)
+	if err != nil {
+		return dto.PaymentResponseDTO{}, err
+	}
+
+	return presenter.FromEntityToResponseDTO(payment), nil
+}
+
+func (c *Controller) ConfirmCashPayment(ctx context.Context, paymentID string, request dto.ConfirmCashPaymentRequestDTO, cashierID string) (dto.PaymentResponseDTO, error) {
+	presenter := presenter.Build()
+
+	payment, err := c.paymentUseCase.ConfirmCashPayment(ctx, paymentID, request.AmountTendered, cashierID)
+	if err != nil {
+		return dto.PaymentResponseDTO{}, err
+	}
+
+	return presenter.FromEntityToResponseDTO(payment), nil
+}
+
+func (c *Controller) FindByID(ctx context.Context, paymentID, customerID string) (dto.PaymentResponseDTO, error) {
+	presenter := presenter.Build()
+
+	payment, err := c.paymentUseCase.FindByID(ctx, paymentID, customerID)
+	if err != nil {
+		return dto.PaymentResponseDTO{}, err
+	}
+
+	return presenter.FromEntityToResponseDTO(payment), nil
+}
+
+func (c *Controller) QRCodePNG(ctx context.Context, paymentID, customerID string, options dto.QRCodeImageRequestDTO) ([]byte, error) {
+	presenter := presenter.Build()
+
+	qrData, err := c.paymentUseCase.QRCodeData(ctx, paymentID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return presenter.QRCodePNG(qrData, options)
+}
+
+func (c *Controller) QRCodeSVG(ctx context.Context, paymentID, customerID string, options dto.QRCodeImageRequestDTO) ([]byte, error) {
+	presenter := presenter.Build()
+
+	qrData, err := c.paymentUseCase.QRCodeData(ctx, paymentID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return presenter.QRCodeSVG(qrData, options)
+}
+
+func (c *Controller) CheckPayment(ctx context.Context, resourceID string) (interface{}, error) {
+	return c.paymentUseCase.CheckPayment(ctx, resourceID)
 }
@@ -6,16 +6,47 @@ import (
 	coreentity "github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
 )
 
+// CheckPaymentRequestDTO is a Mercado Pago notification: the legacy feed
+// sends resource and topic, the Orders API sends type "order" and data.id.
+// Only the type is read from it; the ID comes from the signed query string.
 type CheckPaymentRequestDTO struct {
-	Resource string `json:"resource" binding:"required"`
-	Topic    string `json:"topic" binding:"required"`
+	Resource string                  `json:"resource"`
+	Topic    string                  `json:"topic"`
+	Type     string                  `json:"type"`
+	Data     CheckPaymentRequestData `json:"data"`
+}
+
+type CheckPaymentRequestData struct {
+	ID string `json:"id"`
+}
+
+type CreatePaymentRequestDTO struct {
+	OrderID string             `json:"order_id" binding:"required"`
+	Method  enum.PaymentMethod `json:"method"`
+	PosID   string             `json:"pos_id"`
+}
+
+type ConfirmCashPaymentRequestDTO struct {
+	AmountTendered float64 `json:"amount_tendered" binding:"required,gt=0"`
 }
 
 type PaymentResponseDTO struct {
-	ID      string             `json:"id"`
-	OrderID string             `json:"order_id"`
-	QrCode  string             `json:"qr_code"`
-	Status  enum.PaymentStatus `json:"status"`
+	ID              string               `json:"id"`
+	OrderID         string               `json:"order_id"`
+	QrCode          string               `json:"qr_code"`
+	Status          enum.PaymentStatus   `json:"status"`
+	Method          enum.PaymentMethod   `json:"method"`
+	Amount          float64              `json:"amount"`
+	Cash            *CashConfirmationDTO `json:"cash,omitempty"`
+	ProviderOrderID string               `json:"provider_order_id,omitempty"`
+	PosID           string               `json:"pos_id,omitempty"`
+}
+
+type CashConfirmationDTO struct {
+	AmountTendered float64 `json:"amount_tendered"`
+	ChangeGiven    float64 `json:"change_given"`
+	CashierID      string  `json:"cashier_id"`
+	ConfirmedAt    string  `json:"confirmed_at"`
 }
 
 type PaymentListResponseDTO struct {
@@ -24,27 +55,53 @@ type PaymentListResponseDTO struct {
 }
 
 type PaymentDAO struct {
-	coreentity.Entity
-	OrderID string             `json:"order_id" gorm:"not null;unique"`
-	QrCode  string             `json:"qr_code" gorm:"not null"`
-	Status  enum.PaymentStatus `json:"status" gorm:"not null;default:'PENDING'"`
+	coreentity.Entity `bson:",inline"`
+	OrderID           string                   `json:"order_id" gorm:"not null;unique" bson:"order_id"`
+	QrCode            string                   `json:"qr_code" gorm:"not null" bson:"qr_code"`
+	Status            enum.PaymentStatus       `json:"status" gorm:"not null;default:'PENDING'" bson:"status"`
+	Method            enum.PaymentMethod       `json:"method" gorm:"not null;default:'QR_CODE'" bson:"method"`
+	Amount            float64                  `json:"amount" bson:"amount"`
+	Cash              *entity.CashConfirmation `json:"cash,omitempty" gorm:"embedded;embeddedPrefix:cash_" bson:"cash,omitempty"`
+	ProviderOrderID   string                   `json:"provider_order_id" bson:"provider_order_id,omitempty"`
+	PosID             string                   `json:"pos_id" bson:"pos_id,omitempty"`
+	TraceID           string                   `json:"-" bson:"trace_id,omitempty"`
+	SpanID            string                   `json:"-" bson:"span_id,omitempty"`
 }
 
 func ToPaymentDAO(payment entity.Payment) PaymentDAO {
 	return PaymentDAO{
-		Entity:  payment.Entity,
-		OrderID: payment.OrderID,
-		QrCode:  payment.QrCode,
-		Status:  payment.Status,
+		Entity:          payment.Entity,
+		OrderID:         payment.OrderID,
+		QrCode:          payment.QrCode,
+		Status:          payment.Status,
+		Method:          payment.Method,
+		Amount:          payment.Amount,
+		Cash:            payment.Cash,
+		ProviderOrderID: payment.ProviderOrderID,
+		PosID:           payment.PosID,
+		TraceID:         payment.TraceID,
+		SpanID:          payment.SpanID,
 	}
 }
 
 func FromPaymentDAO(paymentDAO PaymentDAO) entity.Payment {
+	method := paymentDAO.Method
+	if method == "" {
+		method = enum.PaymentMethodQRCode
+	}
+
 	return entity.Payment{
-		Entity:  paymentDAO.Entity,
-		OrderID: paymentDAO.OrderID,
-		QrCode:  paymentDAO.QrCode,
-		Status:  paymentDAO.Status,
+		Entity:          paymentDAO.Entity,
+		OrderID:         paymentDAO.OrderID,
+		QrCode:          paymentDAO.QrCode,
+		Status:          paymentDAO.Status,
+		Method:          method,
+		Amount:          paymentDAO.Amount,
+		Cash:            paymentDAO.Cash,
+		ProviderOrderID: paymentDAO.ProviderOrderID,
+		PosID:           paymentDAO.PosID,
+		TraceID:         paymentDAO.TraceID,
+		SpanID:          paymentDAO.SpanID,
 	}
 }
 
@@ -56,3 +113,9 @@ func EntityListFromDAOList(paymentDAOList []PaymentDAO) []entity.Payment {
 	}
 	return payments
 }
+
+type QRCodeImageRequestDTO struct {
+	Size            int    `form:"size,default=256" binding:"min=64,max=1024"`
+	Margin          int    `form:"margin,default=4" binding:"min=0,max=16"`
+	ErrorCorrection string `form:"ec,default=M" binding:"oneof=L M Q H l m q h"`
+}
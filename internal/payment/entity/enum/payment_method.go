@@ -0,0 +1,20 @@
+package enum
+
+type PaymentMethod string
+
+const (
+	PaymentMethodQRCode PaymentMethod = "QR_CODE"
+	PaymentMethodCash   PaymentMethod = "CASH"
+)
+
+func (p PaymentMethod) String() string {
+	return string(p)
+}
+
+func (p PaymentMethod) IsValid() bool {
+	switch p {
+	case PaymentMethodQRCode, PaymentMethodCash:
+		return true
+	}
+	return false
+}
@@ -6,6 +6,7 @@ const (
 	PaymentStatusPending  PaymentStatus = "PENDING"
 	PaymentStatusApproved PaymentStatus = "APPROVED"
 	PaymentStatusRejected PaymentStatus = "REJECTED"
+	PaymentStatusExpired  PaymentStatus = "EXPIRED"
 )
 
 func (p PaymentStatus) String() string {
@@ -9,9 +9,27 @@ import (
 
 type Payment struct {
 	entity.Entity
-	OrderID string             `json:"order_id" gorm:"not null;unique"`
-	QrCode  string             `json:"qr_code" gorm:"not null"`
-	Status  enum.PaymentStatus `json:"status" gorm:"not null;default:'PENDING'"`
+	OrderID         string             `json:"order_id" gorm:"not null;unique"`
+	QrCode          string             `json:"qr_code" gorm:"not null"`
+	Status          enum.PaymentStatus `json:"status" gorm:"not null;default:'PENDING'"`
+	Method          enum.PaymentMethod `json:"method" gorm:"not null;default:'QR_CODE'"`
+	Amount          float64            `json:"amount"`
+	Cash            *CashConfirmation  `json:"cash,omitempty" gorm:"embedded;embeddedPrefix:cash_"`
+	ProviderOrderID string             `json:"provider_order_id"`
+	PosID           string             `json:"pos_id"`
+	// TraceID and SpanID identify the request that created the payment, so
+	// later provider notifications can be linked back to it.
+	TraceID string `json:"-"`
+	SpanID  string `json:"-"`
+}
+
+// CashConfirmation records what happened at the counter when a cashier
+// confirmed a cash payment.
+type CashConfirmation struct {
+	AmountTendered float64   `json:"amount_tendered" bson:"amount_tendered"`
+	ChangeGiven    float64   `json:"change_given" bson:"change_given"`
+	CashierID      string    `json:"cashier_id" bson:"cashier_id"`
+	ConfirmedAt    time.Time `json:"confirmed_at" bson:"confirmed_at"`
 }
 
 func (p Payment) Build(orderID, qrCode string) Payment {
@@ -26,5 +44,14 @@ func (p Payment) Build(orderID, qrCode string) Payment {
 		OrderID: orderID,
 		QrCode:  qrCode,
 		Status:  enum.PaymentStatusPending,
+		Method:  enum.PaymentMethodQRCode,
 	}
 }
+
+// BuildCash creates a pending cash payment; no QR code is generated for it.
+func (p Payment) BuildCash(orderID string, amount float64) Payment {
+	payment := p.Build(orderID, "")
+	payment.Method = enum.PaymentMethodCash
+	payment.Amount = amount
+	return payment
+}
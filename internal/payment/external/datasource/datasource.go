@@ -8,7 +8,14 @@ import (
 
 type DataSource interface {
 	Create(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error)
+	FindByID(ctx context.Context, id string) (dto.PaymentDAO, error)
 	FindByOrderID(ctx context.Context, orderID string) (dto.PaymentDAO, error)
+	// FindByProviderOrderID returns the payment whose QR code created the
+	// provider order.
+	FindByProviderOrderID(ctx context.Context, providerOrderID string) (dto.PaymentDAO, error)
 	Update(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error)
+	// UpdatePending replaces the payment only while the stored one is still
+	// PENDING, and returns a ConflictError otherwise.
+	UpdatePending(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error)
 	GetAll(ctx context.Context) ([]dto.PaymentDAO, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity/enum"
 	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
 	"gorm.io/gorm"
 )
@@ -35,6 +36,17 @@ func (g *GormDataSource) Create(_ context.Context, payment dto.PaymentDAO) (dto.
 	return payment, nil
 }
 
+func (g *GormDataSource) FindByID(_ context.Context, id string) (dto.PaymentDAO, error) {
+	var payment dto.PaymentDAO
+
+	tx := g.db.First(&payment, "id = ?", id)
+	if tx.Error != nil {
+		return dto.PaymentDAO{}, &apperror.NotFoundError{Msg: "Payment not found"}
+	}
+
+	return payment, nil
+}
+
 func (g *GormDataSource) FindByOrderID(_ context.Context, orderID string) (dto.PaymentDAO, error) {
 	var payment dto.PaymentDAO
 
@@ -46,6 +58,17 @@ func (g *GormDataSource) FindByOrderID(_ context.Context, orderID string) (dto.P
 	return payment, nil
 }
 
+func (g *GormDataSource) FindByProviderOrderID(_ context.Context, providerOrderID string) (dto.PaymentDAO, error) {
+	var payment dto.PaymentDAO
+
+	tx := g.db.First(&payment, "provider_order_id = ?", providerOrderID)
+	if tx.Error != nil {
+		return dto.PaymentDAO{}, &apperror.NotFoundError{Msg: "Payment not found"}
+	}
+
+	return payment, nil
+}
+
 func (g *GormDataSource) Update(_ context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
 	tx := g.db.Save(&payment)
 	if tx.Error != nil {
@@ -55,6 +78,18 @@ func (g *GormDataSource) Update(_ context.Context, payment dto.PaymentDAO) (dto.
 	return payment, nil
 }
 
+func (g *GormDataSource) UpdatePending(_ context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	tx := g.db.Where("id = ? AND status = ?", payment.ID, enum.PaymentStatusPending).Updates(&payment)
+	if tx.Error != nil {
+		return dto.PaymentDAO{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return dto.PaymentDAO{}, &apperror.ConflictError{Msg: "Payment is not pending"}
+	}
+
+	return payment, nil
+}
+
 func (g *GormDataSource) GetAll(_ context.Context) ([]dto.PaymentDAO, error) {
 	var payments []dto.PaymentDAO
 
@@ -0,0 +1,129 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity/enum"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+)
+
+const paymentsCollection = "payments"
+
+type MongoDataSource struct {
+	collection *mongo.Collection
+}
+
+func NewMongo(db *mongo.Database) DataSource {
+	return &MongoDataSource{
+		collection: db.Collection(paymentsCollection),
+	}
+}
+
+// EnsureIndexes creates the payments indexes. order_id is unique among open
+// (pending or approved) payments, so an order cannot be paid twice while a
+// payment that expired or was rejected can still be retried. Notifications
+// look payments up by provider_order_id.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(paymentsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().
+				SetName("order_id_open_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": bson.A{
+					enum.PaymentStatusPending, enum.PaymentStatusApproved,
+				}}}),
+		},
+		{
+			Keys:    bson.D{{Key: "provider_order_id", Value: 1}},
+			Options: options.Index().SetName("provider_order_id").SetSparse(true),
+		},
+	})
+	return err
+}
+
+func (m *MongoDataSource) Create(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	if _, err := m.collection.InsertOne(ctx, payment); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return dto.PaymentDAO{}, &apperror.ConflictError{Msg: "Payment already exists for this order"}
+		}
+		return dto.PaymentDAO{}, err
+	}
+
+	return payment, nil
+}
+
+func (m *MongoDataSource) FindByID(ctx context.Context, id string) (dto.PaymentDAO, error) {
+	return m.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByOrderID returns the most recent payment of the order.
+func (m *MongoDataSource) FindByOrderID(ctx context.Context, orderID string) (dto.PaymentDAO, error) {
+	return m.findOne(ctx, bson.M{"order_id": orderID}, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+func (m *MongoDataSource) FindByProviderOrderID(ctx context.Context, providerOrderID string) (dto.PaymentDAO, error) {
+	return m.findOne(ctx, bson.M{"provider_order_id": providerOrderID})
+}
+
+func (m *MongoDataSource) Update(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	res, err := m.collection.ReplaceOne(ctx, bson.M{"_id": payment.ID}, payment)
+	if err != nil {
+		return dto.PaymentDAO{}, err
+	}
+	if res.MatchedCount == 0 {
+		return dto.PaymentDAO{}, &apperror.NotFoundError{Msg: "Payment not found"}
+	}
+
+	return payment, nil
+}
+
+func (m *MongoDataSource) UpdatePending(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	res, err := m.collection.UpdateOne(ctx,
+		bson.M{"_id": payment.ID, "status": enum.PaymentStatusPending},
+		bson.M{"$set": payment},
+	)
+	if err != nil {
+		return dto.PaymentDAO{}, err
+	}
+	if res.MatchedCount == 0 {
+		return dto.PaymentDAO{}, &apperror.ConflictError{Msg: "Payment is not pending"}
+	}
+
+	return payment, nil
+}
+
+func (m *MongoDataSource) GetAll(ctx context.Context) ([]dto.PaymentDAO, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var payments []dto.PaymentDAO
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (m *MongoDataSource) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (dto.PaymentDAO, error) {
+	var payment dto.PaymentDAO
+
+	err := m.collection.FindOne(ctx, filter, opts...).Decode(&payment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dto.PaymentDAO{}, &apperror.NotFoundError{Msg: "Payment not found"}
+	}
+	if err != nil {
+		return dto.PaymentDAO{}, err
+	}
+
+	return payment, nil
+}
@@ -25,12 +25,30 @@ func (g *Gateway) Create(c context.Context, payment entity.Payment) (entity.Paym
 	created, err := g.datasource.Create(c, paymentDAO)
 
 	if err != nil {
+		var conflictErr *apperror.ConflictError
+		if errors.As(err, &conflictErr) {
+			return entity.Payment{}, conflictErr
+		}
 		return entity.Payment{}, &apperror.InternalError{Msg: err.Error()}
 	}
 
 	return dto.FromPaymentDAO(created), nil
 }
 
+func (g *Gateway) FindByID(c context.Context, id string) (entity.Payment, error) {
+	found, err := g.datasource.FindByID(c, id)
+
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return entity.Payment{}, notFoundErr
+		}
+		return entity.Payment{}, &apperror.InternalError{Msg: "Unexpected error"}
+	}
+
+	return dto.FromPaymentDAO(found), nil
+}
+
 func (g *Gateway) FindByOrderID(c context.Context, orderID string) (entity.Payment, error) {
 	found, err := g.datasource.FindByOrderID(c, orderID)
 
@@ -45,6 +63,36 @@ func (g *Gateway) FindByOrderID(c context.Context, orderID string) (entity.Payme
 	return dto.FromPaymentDAO(found), nil
 }
 
+func (g *Gateway) FindByProviderOrderID(c context.Context, providerOrderID string) (entity.Payment, error) {
+	found, err := g.datasource.FindByProviderOrderID(c, providerOrderID)
+
+	if err != nil {
+		var notFoundErr *apperror.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return entity.Payment{}, notFoundErr
+		}
+		return entity.Payment{}, &apperror.InternalError{Msg: "Unexpected error"}
+	}
+
+	return dto.FromPaymentDAO(found), nil
+}
+
+// UpdatePending saves the payment only if it is still PENDING in storage,
+// so two concurrent settlements cannot both succeed.
+func (g *Gateway) UpdatePending(c context.Context, payment entity.Payment) (entity.Payment, error) {
+	updated, err := g.datasource.UpdatePending(c, dto.ToPaymentDAO(payment))
+
+	if err != nil {
+		var conflictErr *apperror.ConflictError
+		if errors.As(err, &conflictErr) {
+			return entity.Payment{}, conflictErr
+		}
+		return entity.Payment{}, &apperror.InternalError{Msg: err.Error()}
+	}
+
+	return dto.FromPaymentDAO(updated), nil
+}
+
 func (g *Gateway) Update(c context.Context, payment entity.Payment) (entity.Payment, error) {
 	paymentDAO := dto.ToPaymentDAO(payment)
 	updated, err := g.datasource.Update(c, paymentDAO)
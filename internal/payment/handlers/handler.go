@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
 
 	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/controllers"
 	dto "github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity/enum"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/authz"
 	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
 	"github.com/gin-gonic/gin"
@@ -20,31 +27,263 @@ func New(controller *controllers.Controller) *Handler {
 	}
 }
 
+// Create godoc
+// @Summary      Create Payment
+// @Description  Create a payment for an order. QR_CODE (default) generates a Mercado Pago QR code, CASH waits for cashier confirmation and needs payments:create_cash. Users without payments:read_all can only pay for their own orders
+// @Tags         Payment Domain
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreatePaymentRequestDTO true "Order to be paid, payment method and optional POS"
+// @Success      201  {object}  dto.PaymentResponseDTO
+// @Failure      400  {object}  errors.ErrorDTO
+// @Failure      401  {object}  errors.ErrorDTO
+// @Failure      403  {object}  errors.ErrorDTO
+// @Failure      404  {object}  errors.ErrorDTO
+// @Failure      409  {object}  errors.ErrorDTO
+// @Failure      422  {object}  errors.ErrorDTO
+// @Failure      429  {object}  errors.ErrorDTO
+// @Failure      500  {object}  errors.ErrorDTO
+// @Failure      502  {object}  errors.ErrorDTO
+// @Failure      503  {object}  errors.ErrorDTO
+// @Router       /payments [post]
+func (h *Handler) Create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var createDTO dto.CreatePaymentRequestDTO
+	if err := c.ShouldBindJSON(&createDTO); err != nil {
+		helper.HandleBindError(c, "invalid request body", err)
+		return
+	}
+
+	if createDTO.Method == "" {
+		createDTO.Method = enum.PaymentMethodQRCode
+	}
+	if !createDTO.Method.IsValid() {
+		helper.HandleError(c, &apperror.ValidationError{Msg: "unsupported payment method: " + createDTO.Method.String()})
+		return
+	}
+
+	grants, _ := c.Get(authz.ContextKey)
+	if createDTO.Method == enum.PaymentMethodCash && !hasPermission(grants, authz.PermissionPaymentsCreateCash) {
+		helper.HandleError(c, &apperror.ForbiddenError{Msg: "missing permission: " + string(authz.PermissionPaymentsCreateCash)})
+		return
+	}
+
+	var (
+		payment dto.PaymentResponseDTO
+		err     error
+	)
+	switch createDTO.Method {
+	case enum.PaymentMethodCash:
+		payment, err = h.controller.CreateCashByOrderID(ctx, createDTO.OrderID, customerScope(c))
+	default:
+		payment, err = h.controller.CreateByOrderID(ctx, createDTO.OrderID, createDTO.PosID, customerScope(c))
+	}
+	if err != nil {
+		helper.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+// ConfirmCashPayment godoc
+// @Summary      Confirm Cash Payment
+// @Description  Cashier confirms a pending cash payment, recording the amount tendered and the change given. Confirming an approved cash payment again keeps the first confirmation and re-sends the order to Core
+// @Tags         Payment Domain
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                            true  "Payment ID"
+// @Param        request body  dto.ConfirmCashPaymentRequestDTO  true  "Amount tendered by the customer"
+// @Success      200  {object}  dto.PaymentResponseDTO
+// @Failure      400  {object}  errors.ErrorDTO
+// @Failure      401  {object}  errors.ErrorDTO
+// @Failure      403  {object}  errors.ErrorDTO
+// @Failure      404  {object}  errors.ErrorDTO
+// @Failure      409  {object}  errors.ErrorDTO
+// @Failure      429  {object}  errors.ErrorDTO
+// @Failure      500  {object}  errors.ErrorDTO
+// @Router       /payments/{id}/cash/confirm [post]
+func (h *Handler) ConfirmCashPayment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var confirmDTO dto.ConfirmCashPaymentRequestDTO
+	if err := c.ShouldBindJSON(&confirmDTO); err != nil {
+		helper.HandleBindError(c, "invalid request body", err)
+		return
+	}
+
+	confirmedBy := ""
+	if principal, ok := helper.Principal(c); ok {
+		confirmedBy = principal.ID()
+	}
+
+	payment, err := h.controller.ConfirmCashPayment(ctx, c.Param("id"), confirmDTO, confirmedBy)
+	if err != nil {
+		helper.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// FindByID godoc
+// @Summary      Get Payment
+// @Description  Get a payment by ID. Users without payments:read_all only see payments of their own orders; services are not tied to a customer
+// @Tags         Payment Domain
+// @Security BearerAuth
+// @Produce      json
+// @Param        id   path  string  true  "Payment ID"
+// @Success      200  {object}  dto.PaymentResponseDTO
+// @Failure      401  {object}  errors.ErrorDTO
+// @Failure      403  {object}  errors.ErrorDTO
+// @Failure      404  {object}  errors.ErrorDTO
+// @Failure      429  {object}  errors.ErrorDTO
+// @Failure      500  {object}  errors.ErrorDTO
+// @Router       /payments/{id} [get]
+func (h *Handler) FindByID(c *gin.Context) {
+	payment, err := h.controller.FindByID(c.Request.Context(), c.Param("id"), customerScope(c))
+	if err != nil {
+		helper.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// customerScope returns the customer a user without payments:read_all is
+// limited to, or "" when the caller may read any payment.
+func customerScope(c *gin.Context) string {
+	principal, ok := helper.Principal(c)
+	if grants, _ := c.Get(authz.ContextKey); ok && principal.IsUser() && !hasPermission(grants, authz.PermissionPaymentsReadAll) {
+		return principal.ID()
+	}
+	return ""
+}
+
+func hasPermission(grants interface{}, permission authz.Permission) bool {
+	g, ok := grants.(authz.Grants)
+	return ok && g.Has(permission)
+}
+
+// QRCodePNG godoc
+// @Summary      Payment QR Code (PNG)
+// @Description  Render the payment QR code as a PNG image. Users without payments:read_all only see payments of their own orders
+// @Tags         Payment Domain
+// @Security BearerAuth
+// @Produce      png
+// @Param        id      path   string  true   "Payment ID"
+// @Param        size    query  int     false  "Image size in pixels (64-1024), raised when smaller than the QR module count" default(256)
+// @Param        margin  query  int     false  "Quiet zone in modules (0-16)" default(4)
+// @Param        ec      query  string  false  "Error correction level (L, M, Q, H)" default(M)
+// @Success      200
+// @Success      304
+// @Failure      400  {object}  errors.ErrorDTO
+// @Failure      401  {object}  errors.ErrorDTO
+// @Failure      403  {object}  errors.ErrorDTO
+// @Failure      404  {object}  errors.ErrorDTO
+// @Failure      429  {object}  errors.ErrorDTO
+// @Failure      500  {object}  errors.ErrorDTO
+// @Router       /payments/{id}/qrcode.png [get]
+func (h *Handler) QRCodePNG(c *gin.Context) {
+	h.renderQRCode(c, "image/png", h.controller.QRCodePNG)
+}
+
+// QRCodeSVG godoc
+// @Summary      Payment QR Code (SVG)
+// @Description  Render the payment QR code as an SVG image. Users without payments:read_all only see payments of their own orders
+// @Tags         Payment Domain
+// @Security BearerAuth
+// @Produce      image/svg+xml
+// @Param        id      path   string  true   "Payment ID"
+// @Param        size    query  int     false  "Image size in pixels (64-1024), raised when smaller than the QR module count" default(256)
+// @Param        margin  query  int     false  "Quiet zone in modules (0-16)" default(4)
+// @Param        ec      query  string  false  "Error correction level (L, M, Q, H)" default(M)
+// @Success      200
+// @Success      304
+// @Failure      400  {object}  errors.ErrorDTO
+// @Failure      401  {object}  errors.ErrorDTO
+// @Failure      403  {object}  errors.ErrorDTO
+// @Failure      404  {object}  errors.ErrorDTO
+// @Failure      429  {object}  errors.ErrorDTO
+// @Failure      500  {object}  errors.ErrorDTO
+// @Router       /payments/{id}/qrcode.svg [get]
+func (h *Handler) QRCodeSVG(c *gin.Context) {
+	h.renderQRCode(c, "image/svg+xml", h.controller.QRCodeSVG)
+}
+
+type qrCodeRenderer func(ctx context.Context, paymentID, customerID string, options dto.QRCodeImageRequestDTO) ([]byte, error)
+
+// renderQRCode binds the image options, renders the image and serves it with
+// an ETag so clients polling the same payment get 304 responses.
+func (h *Handler) renderQRCode(c *gin.Context, contentType string, render qrCodeRenderer) {
+	var options dto.QRCodeImageRequestDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		helper.HandleBindError(c, "invalid query parameters", err)
+		return
+	}
+
+	image, err := render(c.Request.Context(), c.Param("id"), customerScope(c), options)
+	if err != nil {
+		helper.HandleError(c, err)
+		return
+	}
+
+	sum := sha256.Sum256(image)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, image)
+}
+
 // CheckPayment godoc
 // @Summary      Check Payment [Mercado Pago Integration]
-// @Description  Check the status of a payment by its resource URL
+// @Description  Settle the payment a Mercado Pago notification refers to: a merchant order (topic "merchant_order") or an Orders API order (type "order"), named by the data.id (or legacy id) query parameter and looked up on the configured Mercado Pago host. The body's resource URL and data.id are ignored. Other notification types are acknowledged and ignored
 // @Tags         Payment Domain
-// @Security BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        request body dto.CheckPaymentRequestDTO true "Resource URL to check payment status"
+// @Param        data.id  query  string                     false  "Notified resource ID, covered by x-signature"
+// @Param        id       query  string                     false  "Notified resource ID on the legacy feed"
+// @Param        type     query  string                     false  "Notification type"
+// @Param        topic    query  string                     false  "Notification type on the legacy feed"
+// @Param        request  body   dto.CheckPaymentRequestDTO  true   "Mercado Pago notification"
 // @Success      200
 // @Failure      400  {object}  errors.ErrorDTO
+// @Failure      401  {object}  errors.ErrorDTO
+// @Failure      404  {object}  errors.ErrorDTO
+// @Failure      429  {object}  errors.ErrorDTO
 // @Failure      500  {object}  errors.ErrorDTO
+// @Failure      502  {object}  errors.ErrorDTO
+// @Failure      503  {object}  errors.ErrorDTO
 // @Router       /webhook/payment/check [post]
 func (h *Handler) CheckPayment(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	var checkPaymentDTO dto.CheckPaymentRequestDTO
 	if err := c.ShouldBindJSON(&checkPaymentDTO); err != nil {
-		c.JSON(http.StatusBadRequest, apperror.ErrorDTO{
-			Message:      "invalid request body",
-			MessageError: err.Error(),
-		})
+		helper.HandleBindError(c, "invalid request body", err)
 		return
 	}
 
-	_, err := h.controller.CheckPayment(ctx, checkPaymentDTO.Resource)
+	resource, handled, err := notifiedResource(c, checkPaymentDTO)
+	if err != nil {
+		helper.HandleError(c, err)
+		return
+	}
+	if !handled {
+		c.Writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	_, err = h.controller.CheckPayment(ctx, resource)
 	if err != nil {
 		helper.HandleError(c, err)
 		return
@@ -52,3 +291,22 @@ func (h *Handler) CheckPayment(c *gin.Context) {
 
 	c.Writer.WriteHeader(http.StatusOK)
 }
+
+// notifiedResource picks the ID to look up for a notification: data.id, or
+// id on the legacy feed, from the query string covered by x-signature. The
+// body only names the notification type; its resource URL and data.id are
+// never used, so a tampered body cannot choose what is looked up or where.
+// handled is false for notification types this service does not settle.
+func notifiedResource(c *gin.Context, notification dto.CheckPaymentRequestDTO) (resourceID string, handled bool, err error) {
+	resourceID = cmp.Or(c.Query("data.id"), c.Query("id"))
+	if resourceID == "" {
+		return "", false, &apperror.ValidationError{Msg: "notification has no data.id query parameter"}
+	}
+
+	notificationType := cmp.Or(c.Query("type"), c.Query("topic"), notification.Type, notification.Topic)
+	if notificationType != "order" && notificationType != "merchant_order" {
+		slog.InfoContext(c.Request.Context(), "ignoring notification", "type", notificationType, "data_id", resourceID)
+		return "", false, nil
+	}
+	return resourceID, true, nil
+}
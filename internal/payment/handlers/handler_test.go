@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/http/middleware"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/controllers"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/usecases"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/authz"
+	sharedentity "github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/helper"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/httpclient"
+)
+
+var anyContext = mock.MatchedBy(func(context.Context) bool { return true })
+
+type mockDataSource struct {
+	mock.Mock
+}
+
+func (m *mockDataSource) Create(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, payment)
+	return payment, args.Error(0)
+}
+
+func (m *mockDataSource) FindByID(ctx context.Context, id string) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.PaymentDAO), args.Error(1)
+}
+
+func (m *mockDataSource) FindByOrderID(ctx context.Context, orderID string) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(dto.PaymentDAO), args.Error(1)
+}
+
+func (m *mockDataSource) FindByProviderOrderID(ctx context.Context, providerOrderID string) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, providerOrderID)
+	return args.Get(0).(dto.PaymentDAO), args.Error(1)
+}
+
+func (m *mockDataSource) Update(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, payment)
+	return payment, args.Error(0)
+}
+
+func (m *mockDataSource) UpdatePending(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, payment)
+	return payment, args.Error(0)
+}
+
+func (m *mockDataSource) GetAll(ctx context.Context) ([]dto.PaymentDAO, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]dto.PaymentDAO), args.Error(1)
+}
+
+type mockOrderService struct {
+	mock.Mock
+}
+
+func (m *mockOrderService) FindByID(ctx context.Context, orderID string) (httpclient.Order, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(httpclient.Order), args.Error(1)
+}
+
+func (m *mockOrderService) Update(ctx context.Context, order httpclient.Order) (httpclient.Order, error) {
+	args := m.Called(ctx, order)
+	return args.Get(0).(httpclient.Order), args.Error(1)
+}
+
+type stubProductOrderService struct{}
+
+func (stubProductOrderService) FindByOrderID(context.Context, string) ([]httpclient.ProductOrder, error) {
+	return []httpclient.ProductOrder{{ProductID: "p1", Quantity: 1}}, nil
+}
+
+type stubProductService struct{}
+
+func (stubProductService) FindByIDs(context.Context, []string) ([]httpclient.Product, error) {
+	return []httpclient.Product{{ID: "p1", Name: "Burger", Price: 10.5}}, nil
+}
+
+// newQRCodeRouter mounts the QR code routes behind the same permission check
+// as main, authenticating every request as principal.
+func newQRCodeRouter(ds *mockDataSource, orders *mockOrderService, principal *sharedentity.Principal) *gin.Engine {
+	handler := New(controllers.Build(usecases.Build(gateway.Build(ds), nil, nil, nil, orders, nil)))
+
+	authenticate := func(c *gin.Context) {
+		helper.SetPrincipal(c, principal)
+		c.Next()
+	}
+	read := middleware.RequirePermissions(authz.NewPolicy(nil), authz.PermissionPaymentsRead)
+
+	r := gin.New()
+	r.GET("/payments/:id/qrcode.png", authenticate, read, handler.QRCodePNG)
+	r.GET("/payments/:id/qrcode.svg", authenticate, read, handler.QRCodeSVG)
+	return r
+}
+
+func TestHandler_QRCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	stored := entity.Payment{}.Build("order-1", "00020101021243650016COM.MERCADOLIBRE")
+
+	tests := []struct {
+		name           string
+		principal      *sharedentity.Principal
+		path           string
+		orderCustomer  string
+		expectedStatus int
+		expectedType   string
+		expectOrder    bool
+	}{
+		{
+			name:           "Given a customer reading their own payment, it should render the PNG",
+			principal:      sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "customer-1", UserType: "customer"}),
+			path:           "/qrcode.png",
+			orderCustomer:  "customer-1",
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/png",
+			expectOrder:    true,
+		},
+		{
+			name:           "Given a customer reading their own payment, it should render the SVG",
+			principal:      sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "customer-1", UserType: "customer"}),
+			path:           "/qrcode.svg",
+			orderCustomer:  "customer-1",
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/svg+xml",
+			expectOrder:    true,
+		},
+		{
+			name:           "Given another customer's payment, it should report it as not found",
+			principal:      sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "customer-2", UserType: "customer"}),
+			path:           "/qrcode.png",
+			orderCustomer:  "customer-1",
+			expectedStatus: http.StatusNotFound,
+			expectOrder:    true,
+		},
+		{
+			name:           "Given a user with payments:read_all, it should render any payment",
+			principal:      sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "cashier-1", UserType: "cashier"}),
+			path:           "/qrcode.png",
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/png",
+		},
+		{
+			name:           "Given a service with payments:read, it should render any payment",
+			principal:      sharedentity.NewServicePrincipal(&sharedentity.ServiceClaims{ServiceName: "totem", Scopes: []string{"payments:read"}}),
+			path:           "/qrcode.png",
+			expectedStatus: http.StatusOK,
+			expectedType:   "image/png",
+		},
+		{
+			name:           "Given a service without payments:read, it should forbid the request",
+			principal:      sharedentity.NewServicePrincipal(&sharedentity.ServiceClaims{ServiceName: "reports"}),
+			path:           "/qrcode.png",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &mockDataSource{}
+			orders := &mockOrderService{}
+			ds.On("FindByID", anyContext, stored.ID).Return(dto.ToPaymentDAO(stored), nil)
+			orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1", CustomerID: tt.orderCustomer}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/payments/"+stored.ID+tt.path, nil)
+			w := httptest.NewRecorder()
+			newQRCodeRouter(ds, orders, tt.principal).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+				assert.NotEmpty(t, w.Body.Bytes())
+			}
+			if tt.expectOrder {
+				orders.AssertCalled(t, "FindByID", anyContext, "order-1")
+			} else {
+				orders.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	customer := sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "customer-1", UserType: "customer"})
+
+	tests := []struct {
+		name           string
+		principal      *sharedentity.Principal
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "Given a customer paying their own order in cash without payments:create_cash, it should forbid it",
+			principal:      customer,
+			body:           `{"order_id": "order-1", "method": "CASH"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Given a customer paying another customer's order, it should report it as not found",
+			principal:      sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "customer-2", UserType: "customer"}),
+			body:           `{"order_id": "order-1"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Given a cashier, it should create a cash payment for any order",
+			principal:      sharedentity.NewUserPrincipal(&sharedentity.CustomClaims{UserID: "cashier-1", UserType: "cashier"}),
+			body:           `{"order_id": "order-1", "method": "CASH"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Given a service without payments:create, it should forbid the request",
+			principal:      sharedentity.NewServicePrincipal(&sharedentity.ServiceClaims{ServiceName: "reports", Scopes: []string{"payments:read"}}),
+			body:           `{"order_id": "order-1"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &mockDataSource{}
+			orders := &mockOrderService{}
+			provider := &external.MockQRCodeProvider{}
+			ds.On("Create", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(nil)
+			orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1", CustomerID: "customer-1"}, nil)
+
+			handler := New(controllers.Build(usecases.Build(gateway.Build(ds), provider, stubProductService{}, stubProductOrderService{}, orders, nil)))
+			authenticate := func(c *gin.Context) {
+				helper.SetPrincipal(c, tt.principal)
+				c.Next()
+			}
+			r := gin.New()
+			r.POST("/payments", authenticate, middleware.RequirePermissions(authz.NewPolicy(nil), authz.PermissionPaymentsCreate), handler.Create)
+
+			req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				ds.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				provider.AssertNotCalled(t, "GenerateQRCode", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandler_QRCode_ETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	stored := entity.Payment{}.Build("order-1", "00020101021243650016COM.MERCADOLIBRE")
+	ds := &mockDataSource{}
+	ds.On("FindByID", anyContext, stored.ID).Return(dto.ToPaymentDAO(stored), nil)
+	r := newQRCodeRouter(ds, &mockOrderService{}, sharedentity.NewServicePrincipal(&sharedentity.ServiceClaims{ServiceName: "totem", Scopes: []string{"payments:read"}}))
+
+	send := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/payments/"+stored.ID+path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("/qrcode.png", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	t.Run("Given the current ETag, it should answer 304 without a body", func(t *testing.T) {
+		w := send("/qrcode.png", etag)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("Given a stale ETag, it should render the image again", func(t *testing.T) {
+		w := send("/qrcode.png", `"stale"`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, first.Body.Bytes(), w.Body.Bytes())
+	})
+
+	t.Run("Given other image options, it should use a different ETag", func(t *testing.T) {
+		w := send("/qrcode.png?size=512", etag)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+}
+
+func TestHandler_CheckPayment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name             string
+		query            string
+		body             string
+		expectedStatus   int
+		expectedResource string
+	}{
+		{
+			name:             "Given a legacy merchant order notification, it should check the id in the query",
+			query:            "?id=123&topic=merchant_order",
+			body:             `{"resource": "https://api.mercadolibre.com/merchant_orders/123", "topic": "merchant_order"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResource: "123",
+		},
+		{
+			name:             "Given an Orders API notification, it should check the signed data.id",
+			query:            "?data.id=ORD01JQ&type=order",
+			body:             `{"action": "order.processed", "type": "order", "data": {"id": "ORD01JQ"}}`,
+			expectedStatus:   http.StatusOK,
+			expectedResource: "ORD01JQ",
+		},
+		{
+			name:             "Given a body data.id different from the signed one, it should check the signed one",
+			query:            "?data.id=ORD01JQ&type=order",
+			body:             `{"type": "order", "data": {"id": "ORD-OTHER"}}`,
+			expectedStatus:   http.StatusOK,
+			expectedResource: "ORD01JQ",
+		},
+		{
+			name:           "Given an Orders API notification without query, it should reject it instead of trusting the body",
+			body:           `{"type": "order", "data": {"id": "ORD01JQ"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Given another notification type, it should acknowledge it without checking",
+			query:          "?data.id=123&type=payment",
+			body:           `{"type": "payment", "data": {"id": "123"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Given neither data.id nor id, it should reject the notification",
+			body:           `{"topic": "merchant_order"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &mockDataSource{}
+			provider := &external.MockQRCodeProvider{}
+			provider.On("CheckPayment", anyContext, mock.Anything).Return(dtos.ResponseVerifyOrderDTO{
+				ExternalReference: "order-1",
+				OrderStatus:       "payment_required",
+			}, nil)
+			ds.On("FindByOrderID", anyContext, "order-1").Return(dto.ToPaymentDAO(entity.Payment{}.Build("order-1", "qr-data")), nil)
+
+			handler := New(controllers.Build(usecases.Build(gateway.Build(ds), provider, nil, nil, &mockOrderService{}, nil)))
+			r := gin.New()
+			r.POST("/webhook/payment/check", handler.CheckPayment)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook/payment/check"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedResource != "" {
+				provider.AssertCalled(t, "CheckPayment", anyContext, tt.expectedResource)
+			} else {
+				provider.AssertNotCalled(t, "CheckPayment", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandler_CheckPayment_IgnoresResourceURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Given a signed notification without data.id, it should not call the provider"},
+		{name: "Given a signed data.id, it should check only the ID", query: "?data.id=123&type=merchant_order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &mockDataSource{}
+			provider := &external.MockQRCodeProvider{}
+			provider.On("CheckPayment", anyContext, "123").Return(dtos.ResponseVerifyOrderDTO{
+				ExternalReference: "order-1",
+				OrderStatus:       "payment_required",
+			}, nil)
+			ds.On("FindByOrderID", anyContext, "order-1").Return(dto.ToPaymentDAO(entity.Payment{}.Build("order-1", "qr-data")), nil)
+
+			handler := New(controllers.Build(usecases.Build(gateway.Build(ds), provider, nil, nil, &mockOrderService{}, nil)))
+			r := gin.New()
+			r.POST("/webhook/payment/check", handler.CheckPayment)
+
+			body := `{"resource": "https://attacker.example/merchant_orders/123", "topic": "merchant_order", "data": {"id": "https://attacker.example/x"}}`
+			req := httptest.NewRequest(http.MethodPost, "/webhook/payment/check"+tt.query, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			provider.AssertNotCalled(t, "CheckPayment", anyContext, mock.MatchedBy(func(resource string) bool {
+				return strings.Contains(resource, "attacker")
+			}))
+		})
+	}
+}
@@ -2,8 +2,10 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/httpclient"
+	storeentity "github.com/fiap-161/tc-golunch-payment-service/internal/store/entity"
 )
 
 type ProductService interface {
@@ -18,3 +20,15 @@ type OrderService interface {
 	FindByID(ctx context.Context, orderID string) (httpclient.Order, error)
 	Update(ctx context.Context, order httpclient.Order) (httpclient.Order, error)
 }
+
+type POSService interface {
+	FindPOSByID(ctx context.Context, posID string) (storeentity.POS, error)
+}
+
+// PaymentMetrics records payment lifecycle events.
+type PaymentMetrics interface {
+	PaymentCreated(method string, amount float64)
+	PaymentApproved(method string, amount float64, timeToApproval time.Duration)
+	PaymentRejected(method string)
+	PaymentExpired(method string)
+}
@@ -1,6 +1,8 @@
 package presenter
 
 import (
+	"time"
+
 	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity"
 )
@@ -13,10 +15,25 @@ func Build() *Presenter {
 }
 
 func (p *Presenter) FromEntityToResponseDTO(payment entity.Payment) dto.PaymentResponseDTO {
-	return dto.PaymentResponseDTO{
-		ID:      payment.ID,
-		OrderID: payment.OrderID,
-		QrCode:  payment.QrCode,
-		Status:  payment.Status,
+	response := dto.PaymentResponseDTO{
+		ID:              payment.ID,
+		OrderID:         payment.OrderID,
+		QrCode:          payment.QrCode,
+		Status:          payment.Status,
+		Method:          payment.Method,
+		Amount:          payment.Amount,
+		ProviderOrderID: payment.ProviderOrderID,
+		PosID:           payment.PosID,
+	}
+
+	if payment.Cash != nil {
+		response.Cash = &dto.CashConfirmationDTO{
+			AmountTendered: payment.Cash.AmountTendered,
+			ChangeGiven:    payment.Cash.ChangeGiven,
+			CashierID:      payment.Cash.CashierID,
+			ConfirmedAt:    payment.Cash.ConfirmedAt.Format(time.RFC3339),
+		}
 	}
+
+	return response
 }
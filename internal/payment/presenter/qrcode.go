@@ -0,0 +1,122 @@
+package presenter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+)
+
+var errorCorrectionLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// QRCodePNG renders the QR data as a square PNG of opts.Size pixels with a
+// quiet zone of opts.Margin modules. Every module is drawn as a square of
+// the same whole number of pixels, centred, with the leftover pixels added
+// to the quiet zone; a size smaller than the module count is raised to it
+// so no module is dropped.
+func (p *Presenter) QRCodePNG(qrData string, opts dto.QRCodeImageRequestDTO) ([]byte, error) {
+	modules, err := qrModules(qrData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(modules)
+	size := imageSize(opts.Size, total)
+	scale := size / total
+	offset := (size - scale*total) / 2
+
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for py := offset + y*scale; py < offset+(y+1)*scale; py++ {
+				for px := offset + x*scale; px < offset+(x+1)*scale; px++ {
+					img.SetColorIndex(px, py, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// QRCodeSVG renders the QR data as an SVG scaled to opts.Size pixels. Dark
+// modules are emitted as a single path so the output stays small.
+func (p *Presenter) QRCodeSVG(qrData string, opts dto.QRCodeImageRequestDTO) ([]byte, error) {
+	modules, err := qrModules(qrData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(modules)
+	size := imageSize(opts.Size, total)
+	var path strings.Builder
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, total, total)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#ffffff"/>`)
+	fmt.Fprintf(&buf, `<path fill="#000000" d="%s"/>`, path.String())
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
+
+// imageSize is the requested size, raised to one pixel per module when it is
+// smaller.
+func imageSize(requested, modules int) int {
+	return max(requested, modules)
+}
+
+// qrModules encodes the data and returns the module matrix including the
+// requested quiet zone.
+func qrModules(qrData string, opts dto.QRCodeImageRequestDTO) ([][]bool, error) {
+	level, ok := errorCorrectionLevels[strings.ToUpper(opts.ErrorCorrection)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported error correction level: %s", opts.ErrorCorrection)
+	}
+
+	code, err := qrcode.New(qrData, level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	total := len(bitmap) + 2*opts.Margin
+	modules := make([][]bool, total)
+	for y := range modules {
+		modules[y] = make([]bool, total)
+	}
+	for y, row := range bitmap {
+		copy(modules[y+opts.Margin][opts.Margin:], row)
+	}
+
+	return modules, nil
+}
@@ -0,0 +1,100 @@
+package presenter
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	gozxingqr "github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+)
+
+func TestPresenter_QRCodePNG(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     dto.QRCodeImageRequestDTO
+		expectError bool
+	}{
+		{
+			name:    "Given default options, it should render a PNG of the requested size",
+			options: dto.QRCodeImageRequestDTO{Size: 256, Margin: 4, ErrorCorrection: "M"},
+		},
+		{
+			name:    "Given no margin and lowercase level, it should render a PNG",
+			options: dto.QRCodeImageRequestDTO{Size: 128, Margin: 0, ErrorCorrection: "h"},
+		},
+		{
+			name:        "Given an unknown error correction level, it should return error",
+			options:     dto.QRCodeImageRequestDTO{Size: 128, Margin: 4, ErrorCorrection: "X"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Build().QRCodePNG("00020101021243650016COM.MERCADOLIBRE", tt.options)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			img, decodeErr := png.Decode(bytes.NewReader(got))
+			assert.NoError(t, decodeErr)
+			assert.Equal(t, tt.options.Size, img.Bounds().Dx())
+			assert.Equal(t, tt.options.Size, img.Bounds().Dy())
+		})
+	}
+}
+
+func TestPresenter_QRCodePNG_Decodes(t *testing.T) {
+	// A Mercado Pago EMV payload long enough to need more modules than the
+	// smallest allowed size once the widest quiet zone is added.
+	qrData := "00020101021243650016COM.MERCADOLIBRE02013063638f1192a-5fd1-4180-a180-8bcae3556bc35204000053039865802BR5925IZABEL AAAA DE MELO6009SAO PAULO62070503***6304" +
+		strings.Repeat("1D3D", 20)
+
+	tests := []dto.QRCodeImageRequestDTO{
+		{Size: 256, Margin: 4, ErrorCorrection: "M"},
+		{Size: 100, Margin: 4, ErrorCorrection: "M"},
+		{Size: 64, Margin: 16, ErrorCorrection: "H"},
+		{Size: 1024, Margin: 0, ErrorCorrection: "L"},
+	}
+
+	for _, options := range tests {
+		t.Run(fmt.Sprintf("Given size %d, margin %d and level %s, it should decode to the payload", options.Size, options.Margin, options.ErrorCorrection), func(t *testing.T) {
+			got, err := Build().QRCodePNG(qrData, options)
+			require.NoError(t, err)
+
+			img, err := png.Decode(bytes.NewReader(got))
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, img.Bounds().Dx(), options.Size)
+
+			bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+			require.NoError(t, err)
+			result, err := gozxingqr.NewQRCodeReader().Decode(bitmap, map[gozxing.DecodeHintType]interface{}{
+				gozxing.DecodeHintType_PURE_BARCODE: true,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, qrData, result.GetText())
+		})
+	}
+}
+
+func TestPresenter_QRCodeSVG(t *testing.T) {
+	got, err := Build().QRCodeSVG("00020101021243650016COM.MERCADOLIBRE", dto.QRCodeImageRequestDTO{
+		Size:            300,
+		Margin:          2,
+		ErrorCorrection: "Q",
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(got), `width="300" height="300"`)
+	assert.Contains(t, string(got), `<path fill="#000000" d="M`)
+}
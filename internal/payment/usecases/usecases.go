@@ -2,8 +2,14 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
 
@@ -12,6 +18,7 @@ import (
 	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/gateway"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/interfaces"
 	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/tracing"
 )
 
 type UseCases struct {
@@ -20,6 +27,18 @@ type UseCases struct {
 	productService      interfaces.ProductService
 	productOrderService interfaces.ProductOrderService
 	orderService        interfaces.OrderService
+	posService          interfaces.POSService
+	metrics             interfaces.PaymentMetrics
+}
+
+// Option customizes the use cases at construction time.
+type Option func(*UseCases)
+
+// WithMetrics records payment lifecycle events.
+func WithMetrics(metrics interfaces.PaymentMetrics) Option {
+	return func(u *UseCases) {
+		u.metrics = metrics
+	}
 }
 
 func Build(
@@ -28,98 +47,440 @@ func Build(
 	productService interfaces.ProductService,
 	productOrderService interfaces.ProductOrderService,
 	orderService interfaces.OrderService,
+	posService interfaces.POSService,
+	opts ...Option,
 ) *UseCases {
-	return &UseCases{
+	useCases := &UseCases{
 		paymentGateway:      paymentGateway,
 		qrCodeProvider:      qrCodeProvider,
 		productService:      productService,
 		productOrderService: productOrderService,
 		orderService:        orderService,
+		posService:          posService,
+		metrics:             noopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(useCases)
 	}
+	return useCases
 }
 
-func (u *UseCases) CreateByOrderID(ctx context.Context, orderID string) (entity.Payment, error) {
-	productOrders, productOrderErr := u.productOrderService.FindByOrderID(ctx, orderID)
-	if productOrderErr != nil {
-		return entity.Payment{}, productOrderErr
-	}
+// CreateByOrderID generates the QR code on the POS identified by posID. An
+// empty posID uses the deployment's default POS. When customerID is set the
+// order must belong to that customer, as in FindByID.
+func (u *UseCases) CreateByOrderID(ctx context.Context, orderID, posID, customerID string) (entity.Payment, error) {
+	ctx, span := tracing.Start(ctx, "usecases.CreateByOrderID")
+	defer span.End()
 
-	var productIDs []string
-	for _, po := range productOrders {
-		productIDs = append(productIDs, po.ProductID)
+	if ownerErr := u.requireOrderOwner(ctx, orderID, customerID); ownerErr != nil {
+		return entity.Payment{}, ownerErr
 	}
 
-	products, productsErr := u.productService.FindByIDs(ctx, productIDs)
-	if productsErr != nil {
-		return entity.Payment{}, productsErr
+	var pos entities.POS
+	if posID != "" {
+		found, posErr := u.posService.FindPOSByID(ctx, posID)
+		if posErr != nil {
+			return entity.Payment{}, posErr
+		}
+		pos = entities.POS{
+			CollectorUserID: found.CollectorUserID,
+			ExternalPosID:   found.ExternalPosID,
+			CredentialsRef:  found.CredentialsRef,
+		}
 	}
 
-	var items []entities.Item
-	for _, po := range productOrders {
-		for _, product := range products {
-			if po.ProductID == product.ID {
-				items = append(items, entities.Item{
-					ID:          product.ID,
-					Name:        product.Name,
-					Price:       product.Price,
-					Description: product.Name, // Usar Name como Description já que não tem Description
-					Quantity:    po.Quantity,
-					Amount:      product.Price * float64(po.Quantity),
-				})
-				break
-			}
-		}
+	items, itemsErr := u.orderItems(ctx, orderID)
+	if itemsErr != nil {
+		return entity.Payment{}, itemsErr
 	}
 
+	var payment entity.Payment
+	payment = payment.Build(orderID, "")
+
 	qrCode, qrCodeErr := u.qrCodeProvider.GenerateQRCode(ctx, entities.GenerateQRCodeParams{
-		OrderID: orderID,
-		Items:   items,
+		OrderID:   orderID,
+		PaymentID: payment.ID,
+		Items:     items,
+		POS:       pos,
 	})
 	if qrCodeErr != nil {
 		return entity.Payment{}, qrCodeErr
 	}
 
+	payment.QrCode = qrCode.QRData
+	payment.ProviderOrderID = qrCode.ProviderOrderID
+	payment.PosID = posID
+	payment.Amount = totalAmount(items)
+	payment.TraceID, payment.SpanID = tracing.IDs(ctx)
+
+	createdPayment, createErr := u.paymentGateway.Create(ctx, payment)
+	if createErr != nil {
+		return entity.Payment{}, createErr
+	}
+
+	u.paymentCreated(ctx, createdPayment)
+	return createdPayment, nil
+}
+
+// CreateCashByOrderID registers a cash payment for the order. No provider is
+// called: the payment stays PENDING until a cashier confirms it at the counter.
+// customerID scopes the order like CreateByOrderID.
+func (u *UseCases) CreateCashByOrderID(ctx context.Context, orderID, customerID string) (entity.Payment, error) {
+	ctx, span := tracing.Start(ctx, "usecases.CreateCashByOrderID")
+	defer span.End()
+
+	if ownerErr := u.requireOrderOwner(ctx, orderID, customerID); ownerErr != nil {
+		return entity.Payment{}, ownerErr
+	}
+
+	items, itemsErr := u.orderItems(ctx, orderID)
+	if itemsErr != nil {
+		return entity.Payment{}, itemsErr
+	}
+
 	var payment entity.Payment
-	createdPayment, createErr := u.paymentGateway.Create(ctx, payment.Build(orderID, qrCode))
+	payment = payment.BuildCash(orderID, totalAmount(items))
+	payment.TraceID, payment.SpanID = tracing.IDs(ctx)
+
+	createdPayment, createErr := u.paymentGateway.Create(ctx, payment)
 	if createErr != nil {
 		return entity.Payment{}, createErr
 	}
 
+	u.paymentCreated(ctx, createdPayment)
 	return createdPayment, nil
 }
 
-func (u *UseCases) CheckPayment(ctx context.Context, requestUrl string) (interface{}, error) {
-	if requestUrl == "" {
-		return nil, &apperror.ValidationError{Msg: "Request URL is required"}
+func (u *UseCases) paymentCreated(ctx context.Context, payment entity.Payment) {
+	u.metrics.PaymentCreated(payment.Method.String(), payment.Amount)
+	slog.InfoContext(ctx, "payment created",
+		"payment_id", payment.ID,
+		"order_id", payment.OrderID,
+		"method", payment.Method,
+		"amount", payment.Amount,
+		"pos_id", payment.PosID,
+	)
+}
+
+// ConfirmCashPayment records the cash handed over at the counter and approves
+// the payment, notifying Core exactly like a provider-confirmed payment. The
+// approval only applies while the stored payment is still pending, so when
+// two cashiers confirm at once the second gets a ConflictError. Confirming an
+// approved cash payment again keeps the first confirmation and re-sends the
+// order to Core, so a cashier retrying after Core failed still gets the order
+// moving.
+func (u *UseCases) ConfirmCashPayment(ctx context.Context, paymentID string, amountTendered float64, cashierID string) (entity.Payment, error) {
+	ctx, span := tracing.Start(ctx, "usecases.ConfirmCashPayment")
+	defer span.End()
+
+	payment, paymentErr := u.paymentGateway.FindByID(ctx, paymentID)
+	if paymentErr != nil {
+		return entity.Payment{}, paymentErr
+	}
+
+	if payment.Method != enum.PaymentMethodCash {
+		return entity.Payment{}, &apperror.ValidationError{Msg: "Payment is not a cash payment"}
+	}
+	if payment.Status == enum.PaymentStatusApproved {
+		return u.approve(ctx, payment)
+	}
+	if payment.Status != enum.PaymentStatusPending {
+		return entity.Payment{}, &apperror.ConflictError{Msg: "Payment is not pending"}
+	}
+	if roundCents(amountTendered) < payment.Amount {
+		return entity.Payment{}, &apperror.ValidationError{Msg: "Amount tendered is lower than the payment amount"}
+	}
+
+	now := time.Now()
+	payment.Cash = &entity.CashConfirmation{
+		AmountTendered: roundCents(amountTendered),
+		ChangeGiven:    roundCents(amountTendered - payment.Amount),
+		CashierID:      cashierID,
+		ConfirmedAt:    now,
 	}
+	payment.UpdatedAt = now
+
+	return u.approve(ctx, payment)
+}
+
+// FindByID returns a payment. When customerID is set the payment is only
+// returned if its order belongs to that customer; otherwise it is reported
+// as not found so other customers' payments are not disclosed.
+func (u *UseCases) FindByID(ctx context.Context, paymentID, customerID string) (entity.Payment, error) {
+	ctx, span := tracing.Start(ctx, "usecases.FindByID")
+	defer span.End()
 
-	response, err := u.qrCodeProvider.CheckPayment(ctx, requestUrl)
+	payment, paymentErr := u.paymentGateway.FindByID(ctx, paymentID)
+	if paymentErr != nil {
+		return entity.Payment{}, paymentErr
+	}
+
+	owned, ownerErr := u.ownsOrder(ctx, payment.OrderID, customerID)
+	if ownerErr != nil {
+		return entity.Payment{}, ownerErr
+	}
+	if !owned {
+		return entity.Payment{}, &apperror.NotFoundError{Msg: "Payment not found"}
+	}
+
+	return payment, nil
+}
+
+// requireOrderOwner reports another customer's order as not found, so its
+// existence is not disclosed. An empty customerID is not tied to a customer.
+func (u *UseCases) requireOrderOwner(ctx context.Context, orderID, customerID string) error {
+	owned, ownerErr := u.ownsOrder(ctx, orderID, customerID)
+	if ownerErr != nil {
+		return ownerErr
+	}
+	if !owned {
+		return &apperror.NotFoundError{Msg: "Order not found"}
+	}
+	return nil
+}
+
+// ownsOrder reports whether the order belongs to customerID; any caller not
+// tied to a customer owns every order.
+func (u *UseCases) ownsOrder(ctx context.Context, orderID, customerID string) (bool, error) {
+	if customerID == "" {
+		return true, nil
+	}
+
+	order, orderErr := u.orderService.FindByID(ctx, orderID)
+	if orderErr != nil {
+		return false, orderErr
+	}
+	return order.CustomerID == customerID, nil
+}
+
+// QRCodeData returns the stored QR payload of a payment so it can be rendered
+// as an image. customerID scopes the lookup like FindByID.
+func (u *UseCases) QRCodeData(ctx context.Context, paymentID, customerID string) (string, error) {
+	payment, paymentErr := u.FindByID(ctx, paymentID, customerID)
+	if paymentErr != nil {
+		return "", paymentErr
+	}
+
+	if payment.QrCode == "" {
+		return "", &apperror.NotFoundError{Msg: "Payment has no QR code"}
+	}
+
+	return payment.QrCode, nil
+}
+
+// CheckPayment settles the payment a provider notification refers to. Only
+// the QR payment that created the notified provider order is settled, so a
+// late notification for an earlier attempt cannot approve a newer payment of
+// the same order, and cash payments are left to the cashier. The settlement
+// span is linked to the trace that created the payment, so both halves of
+// the flow can be found from either side.
+func (u *UseCases) CheckPayment(ctx context.Context, resourceID string) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "usecases.CheckPayment")
+	defer span.End()
+
+	if resourceID == "" {
+		return nil, &apperror.ValidationError{Msg: "Resource ID is required"}
+	}
+
+	response, err := u.qrCodeProvider.CheckPayment(ctx, resourceID)
 	if err != nil {
 		return nil, fmt.Errorf("error checking payment: %w", err)
 	}
 
-	payment, paymentErr := u.paymentGateway.FindByOrderID(ctx, response.ExternalReference)
+	slog.InfoContext(ctx, "payment status received from provider",
+		"order_id", response.ExternalReference,
+		"order_status", response.OrderStatus,
+	)
+
+	payment, paymentErr := u.notifiedPayment(ctx, response)
 	if paymentErr != nil {
 		return nil, paymentErr
 	}
-	if response.OrderStatus == "paid" {
-		payment.Status = enum.PaymentStatusApproved
-		_, updateErr := u.paymentGateway.Update(ctx, payment)
-		if updateErr != nil {
-			return nil, updateErr
+	if payment.Method != enum.PaymentMethodQRCode || payment.OrderID != response.ExternalReference {
+		slog.WarnContext(ctx, "notification does not match a QR payment, not settling",
+			"payment_id", payment.ID,
+			"method", payment.Method,
+			"order_id", payment.OrderID,
+			"external_reference", response.ExternalReference,
+		)
+		return response, nil
+	}
+
+	ctx, settleSpan := tracing.Start(ctx, "payment.settle", tracing.LinkTo(payment.TraceID, payment.SpanID)...)
+	defer settleSpan.End()
+
+	switch providerStatus(response.OrderStatus) {
+	case enum.PaymentStatusApproved:
+		if _, approveErr := u.approve(ctx, payment); approveErr != nil && !settledConcurrently(ctx, payment, approveErr) {
+			return nil, approveErr
+		}
+	case enum.PaymentStatusRejected:
+		if closeErr := u.close(ctx, payment, enum.PaymentStatusRejected); closeErr != nil && !settledConcurrently(ctx, payment, closeErr) {
+			return nil, closeErr
 		}
+	case enum.PaymentStatusExpired:
+		if closeErr := u.close(ctx, payment, enum.PaymentStatusExpired); closeErr != nil && !settledConcurrently(ctx, payment, closeErr) {
+			return nil, closeErr
+		}
+	}
+
+	return response, nil
+}
+
+// notifiedPayment finds the payment whose QR code created the notified
+// provider order. Legacy merchant orders carry no ID stored with the
+// payment, so they fall back to the latest payment of the order.
+func (u *UseCases) notifiedPayment(ctx context.Context, response dtos.ResponseVerifyOrderDTO) (entity.Payment, error) {
+	if response.ProviderOrderID != "" {
+		return u.paymentGateway.FindByProviderOrderID(ctx, response.ProviderOrderID)
+	}
+	return u.paymentGateway.FindByOrderID(ctx, response.ExternalReference)
+}
+
+// settledConcurrently reports whether err means another notification or a
+// cashier settled the payment first, which leaves nothing to do.
+func settledConcurrently(ctx context.Context, payment entity.Payment, err error) bool {
+	var conflictErr *apperror.ConflictError
+	if !errors.As(err, &conflictErr) {
+		return false
+	}
+	slog.InfoContext(ctx, "payment already settled", "payment_id", payment.ID, "order_id", payment.OrderID)
+	return true
+}
+
+// providerStatus maps a provider order status to the payment status it
+// settles on; in-progress statuses map to pending.
+func providerStatus(orderStatus string) enum.PaymentStatus {
+	switch strings.ToLower(orderStatus) {
+	case "paid":
+		return enum.PaymentStatusApproved
+	case "expired":
+		return enum.PaymentStatusExpired
+	case "canceled", "cancelled", "failed", "rejected", "reverted":
+		return enum.PaymentStatusRejected
+	}
+	return enum.PaymentStatusPending
+}
+
+// close settles a pending payment the provider reported as rejected or
+// expired. Payments that are no longer pending are left untouched, so
+// webhook retries are harmless.
+func (u *UseCases) close(ctx context.Context, payment entity.Payment, status enum.PaymentStatus) error {
+	if payment.Status != enum.PaymentStatusPending {
+		return nil
+	}
+
+	payment.Status = status
+	payment.UpdatedAt = time.Now()
+	if _, updateErr := u.paymentGateway.UpdatePending(ctx, payment); updateErr != nil {
+		return updateErr
+	}
+
+	if status == enum.PaymentStatusExpired {
+		u.metrics.PaymentExpired(payment.Method.String())
+	} else {
+		u.metrics.PaymentRejected(payment.Method.String())
+	}
+	slog.InfoContext(ctx, "payment closed",
+		"payment_id", payment.ID,
+		"order_id", payment.OrderID,
+		"status", status,
+	)
+	return nil
+}
+
+// approve marks the payment as approved and moves the order to RECEIVED on Core.
+// A pending payment is only approved if it is still pending in storage; an
+// approved one is saved again so a retried notification re-syncs Core.
+func (u *UseCases) approve(ctx context.Context, payment entity.Payment) (entity.Payment, error) {
+	alreadyApproved := payment.Status == enum.PaymentStatusApproved
+	payment.Status = enum.PaymentStatusApproved
+	save := u.paymentGateway.UpdatePending
+	if alreadyApproved {
+		save = u.paymentGateway.Update
+	}
+	updated, updateErr := save(ctx, payment)
+	if updateErr != nil {
+		return entity.Payment{}, updateErr
+	}
+
+	order, orderErr := u.orderService.FindByID(ctx, payment.OrderID)
+	if orderErr != nil {
+		return entity.Payment{}, orderErr
+	}
 
-		order, orderErr := u.orderService.FindByID(ctx, response.ExternalReference)
-		if orderErr != nil {
-			return nil, orderErr
+	order.Status = "RECEIVED"
+	_, updateOrderErr := u.orderService.Update(ctx, order)
+	if updateOrderErr != nil {
+		return entity.Payment{}, updateOrderErr
+	}
+
+	if !alreadyApproved {
+		var timeToApproval time.Duration
+		if !updated.CreatedAt.IsZero() {
+			timeToApproval = time.Since(updated.CreatedAt)
 		}
+		u.metrics.PaymentApproved(updated.Method.String(), updated.Amount, timeToApproval)
+	}
+	slog.InfoContext(ctx, "payment approved",
+		"payment_id", updated.ID,
+		"order_id", updated.OrderID,
+		"method", updated.Method,
+	)
+	return updated, nil
+}
+
+func (u *UseCases) orderItems(ctx context.Context, orderID string) ([]entities.Item, error) {
+	productOrders, productOrderErr := u.productOrderService.FindByOrderID(ctx, orderID)
+	if productOrderErr != nil {
+		return nil, productOrderErr
+	}
+
+	var productIDs []string
+	for _, po := range productOrders {
+		productIDs = append(productIDs, po.ProductID)
+	}
+
+	products, productsErr := u.productService.FindByIDs(ctx, productIDs)
+	if productsErr != nil {
+		return nil, productsErr
+	}
 
-		order.Status = "RECEIVED"
-		_, updateOrderErr := u.orderService.Update(ctx, order)
-		if updateOrderErr != nil {
-			return nil, updateOrderErr
+	var items []entities.Item
+	for _, po := range productOrders {
+		for _, product := range products {
+			if po.ProductID == product.ID {
+				items = append(items, entities.Item{
+					ID:          product.ID,
+					Name:        product.Name,
+					Price:       product.Price,
+					Description: product.Name, // Usar Name como Description já que não tem Description
+					Quantity:    po.Quantity,
+					Amount:      product.Price * float64(po.Quantity),
+				})
+				break
+			}
 		}
 	}
 
-	return response, nil
+	return items, nil
+}
+
+func totalAmount(items []entities.Item) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Amount
+	}
+	return roundCents(total)
 }
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) PaymentCreated(string, float64)                 {}
+func (noopMetrics) PaymentApproved(string, float64, time.Duration) {}
+func (noopMetrics) PaymentRejected(string)                         {}
+func (noopMetrics) PaymentExpired(string)                          {}
@@ -0,0 +1,630 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/entity/enum"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/payment/gateway"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/httpclient"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/tracing"
+	storeentity "github.com/fiap-161/tc-golunch-payment-service/internal/store/entity"
+)
+
+// anyContext matches the context handed to dependencies, which the use
+// cases derive from the caller's to carry their spans.
+var anyContext = mock.MatchedBy(func(context.Context) bool { return true })
+
+type mockDataSource struct {
+	mock.Mock
+}
+
+func (m *mockDataSource) Create(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, payment)
+	return payment, args.Error(0)
+}
+
+func (m *mockDataSource) FindByID(ctx context.Context, id string) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.PaymentDAO), args.Error(1)
+}
+
+func (m *mockDataSource) FindByOrderID(ctx context.Context, orderID string) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(dto.PaymentDAO), args.Error(1)
+}
+
+func (m *mockDataSource) FindByProviderOrderID(ctx context.Context, providerOrderID string) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, providerOrderID)
+	return args.Get(0).(dto.PaymentDAO), args.Error(1)
+}
+
+func (m *mockDataSource) Update(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, payment)
+	return payment, args.Error(0)
+}
+
+func (m *mockDataSource) UpdatePending(ctx context.Context, payment dto.PaymentDAO) (dto.PaymentDAO, error) {
+	args := m.Called(ctx, payment)
+	return payment, args.Error(0)
+}
+
+func (m *mockDataSource) GetAll(ctx context.Context) ([]dto.PaymentDAO, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]dto.PaymentDAO), args.Error(1)
+}
+
+type mockProductService struct {
+	mock.Mock
+}
+
+func (m *mockProductService) FindByIDs(ctx context.Context, productIDs []string) ([]httpclient.Product, error) {
+	args := m.Called(ctx, productIDs)
+	return args.Get(0).([]httpclient.Product), args.Error(1)
+}
+
+type mockProductOrderService struct {
+	mock.Mock
+}
+
+func (m *mockProductOrderService) FindByOrderID(ctx context.Context, orderID string) ([]httpclient.ProductOrder, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]httpclient.ProductOrder), args.Error(1)
+}
+
+type mockOrderService struct {
+	mock.Mock
+}
+
+func (m *mockOrderService) FindByID(ctx context.Context, orderID string) (httpclient.Order, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(httpclient.Order), args.Error(1)
+}
+
+func (m *mockOrderService) Update(ctx context.Context, order httpclient.Order) (httpclient.Order, error) {
+	args := m.Called(ctx, order)
+	return args.Get(0).(httpclient.Order), args.Error(1)
+}
+
+type mockPOSService struct {
+	mock.Mock
+}
+
+func (m *mockPOSService) FindPOSByID(ctx context.Context, posID string) (storeentity.POS, error) {
+	args := m.Called(ctx, posID)
+	return args.Get(0).(storeentity.POS), args.Error(1)
+}
+
+func TestUseCases_CreateByOrderID(t *testing.T) {
+	tests := []struct {
+		name        string
+		posID       string
+		customerID  string
+		pos         storeentity.POS
+		posErr      error
+		expectedPOS entities.POS
+		expectedErr error
+	}{
+		{
+			name:        "Given no POS, it should use the provider defaults",
+			expectedPOS: entities.POS{},
+		},
+		{
+			name:        "Given the order owner, it should create the payment",
+			customerID:  "customer-1",
+			expectedPOS: entities.POS{},
+		},
+		{
+			name:        "Given another customer's order, it should report not found before calling the provider",
+			customerID:  "customer-2",
+			expectedErr: &apperror.NotFoundError{Msg: "Order not found"},
+		},
+		{
+			name:  "Given a registered POS, it should address its collector and terminal",
+			posID: "totem-01",
+			pos: storeentity.POS{
+				CollectorUserID: "collector-2",
+				ExternalPosID:   "STORE2POS1",
+				CredentialsRef:  "store2",
+			},
+			expectedPOS: entities.POS{
+				CollectorUserID: "collector-2",
+				ExternalPosID:   "STORE2POS1",
+				CredentialsRef:  "store2",
+			},
+		},
+		{
+			name:        "Given an unknown POS, it should fail before calling the provider",
+			posID:       "unknown",
+			posErr:      &apperror.NotFoundError{Msg: "POS not found"},
+			expectedErr: &apperror.NotFoundError{Msg: "POS not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ds := &mockDataSource{}
+			productOrders := &mockProductOrderService{}
+			products := &mockProductService{}
+			provider := &external.MockQRCodeProvider{}
+			posService := &mockPOSService{}
+
+			posService.On("FindPOSByID", anyContext, tt.posID).Return(tt.pos, tt.posErr)
+			productOrders.On("FindByOrderID", anyContext, "order-1").Return([]httpclient.ProductOrder{
+				{ProductID: "p1", Quantity: 1},
+			}, nil)
+			products.On("FindByIDs", anyContext, []string{"p1"}).Return([]httpclient.Product{
+				{ID: "p1", Name: "Burger", Price: 10.5},
+			}, nil)
+			provider.On("GenerateQRCode", anyContext, mock.AnythingOfType("entities.GenerateQRCodeParams")).
+				Return(entities.QRCode{ProviderOrderID: "mp-order-1", QRData: "qr-data"}, nil)
+			ds.On("Create", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(nil)
+			orders := &mockOrderService{}
+			orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1", CustomerID: "customer-1"}, nil)
+
+			useCases := Build(gateway.Build(ds), provider, products, productOrders, orders, posService)
+
+			payment, err := useCases.CreateByOrderID(ctx, "order-1", tt.posID, tt.customerID)
+
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				provider.AssertNotCalled(t, "GenerateQRCode", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			params := provider.Calls[0].Arguments.Get(1).(entities.GenerateQRCodeParams)
+			assert.Equal(t, tt.expectedPOS, params.POS)
+			assert.Equal(t, payment.ID, params.PaymentID, "idempotency should be keyed by the payment, not the order")
+			assert.Equal(t, "qr-data", payment.QrCode)
+			assert.Equal(t, "mp-order-1", payment.ProviderOrderID)
+			assert.Equal(t, tt.posID, payment.PosID)
+			assert.Equal(t, 10.5, payment.Amount)
+		})
+	}
+}
+
+func TestUseCases_CreateCashByOrderID(t *testing.T) {
+	ctx := context.Background()
+	ds := &mockDataSource{}
+	productOrders := &mockProductOrderService{}
+	products := &mockProductService{}
+
+	productOrders.On("FindByOrderID", anyContext, "order-1").Return([]httpclient.ProductOrder{
+		{ProductID: "p1", Quantity: 2},
+		{ProductID: "p2", Quantity: 1},
+	}, nil)
+	products.On("FindByIDs", anyContext, []string{"p1", "p2"}).Return([]httpclient.Product{
+		{ID: "p1", Name: "Burger", Price: 10.5},
+		{ID: "p2", Name: "Soda", Price: 4.25},
+	}, nil)
+	ds.On("Create", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(nil)
+
+	useCases := Build(gateway.Build(ds), nil, products, productOrders, &mockOrderService{}, &mockPOSService{})
+
+	payment, err := useCases.CreateCashByOrderID(ctx, "order-1", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, enum.PaymentMethodCash, payment.Method)
+	assert.Equal(t, enum.PaymentStatusPending, payment.Status)
+	assert.Equal(t, 25.25, payment.Amount)
+	assert.Empty(t, payment.QrCode)
+}
+
+func TestUseCases_ConfirmCashPayment(t *testing.T) {
+	pending := entity.Payment{}.BuildCash("order-1", 25.25)
+
+	qrPayment := entity.Payment{}.Build("order-1", "qr-data")
+
+	expired := entity.Payment{}.BuildCash("order-1", 25.25)
+	expired.Status = enum.PaymentStatusExpired
+
+	tests := []struct {
+		name           string
+		stored         entity.Payment
+		amountTendered float64
+		updateErr      error
+		expectedChange float64
+		expectedErr    error
+	}{
+		{
+			name:           "Given a pending cash payment and enough cash, it should approve and notify core",
+			stored:         pending,
+			amountTendered: 30,
+			expectedChange: 4.75,
+		},
+		{
+			name:           "Given the exact amount, it should approve with no change",
+			stored:         pending,
+			amountTendered: 25.25,
+			expectedChange: 0,
+		},
+		{
+			name:           "Given not enough cash, it should fail validation",
+			stored:         pending,
+			amountTendered: 20,
+			expectedErr:    &apperror.ValidationError{Msg: "Amount tendered is lower than the payment amount"},
+		},
+		{
+			name:           "Given a QR code payment, it should fail validation",
+			stored:         qrPayment,
+			amountTendered: 30,
+			expectedErr:    &apperror.ValidationError{Msg: "Payment is not a cash payment"},
+		},
+		{
+			name:           "Given an expired payment, it should conflict",
+			stored:         expired,
+			amountTendered: 30,
+			expectedErr:    &apperror.ConflictError{Msg: "Payment is not pending"},
+		},
+		{
+			name:           "Given another cashier confirmed it concurrently, it should conflict without notifying core",
+			stored:         pending,
+			amountTendered: 30,
+			updateErr:      &apperror.ConflictError{Msg: "Payment is not pending"},
+			expectedErr:    &apperror.ConflictError{Msg: "Payment is not pending"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ds := &mockDataSource{}
+			orders := &mockOrderService{}
+
+			ds.On("FindByID", anyContext, tt.stored.ID).Return(dto.ToPaymentDAO(tt.stored), nil)
+			ds.On("UpdatePending", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(tt.updateErr)
+			orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1", Status: "PENDING"}, nil)
+			orders.On("Update", anyContext, httpclient.Order{ID: "order-1", Status: "RECEIVED"}).Return(httpclient.Order{ID: "order-1", Status: "RECEIVED"}, nil)
+
+			useCases := Build(gateway.Build(ds), nil, nil, nil, orders, &mockPOSService{})
+
+			payment, err := useCases.ConfirmCashPayment(ctx, tt.stored.ID, tt.amountTendered, "cashier-1")
+
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				if tt.updateErr == nil {
+					ds.AssertNotCalled(t, "UpdatePending", mock.Anything, mock.Anything)
+				}
+				orders.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, enum.PaymentStatusApproved, payment.Status)
+			assert.Equal(t, tt.amountTendered, payment.Cash.AmountTendered)
+			assert.Equal(t, tt.expectedChange, payment.Cash.ChangeGiven)
+			assert.Equal(t, "cashier-1", payment.Cash.CashierID)
+			orders.AssertCalled(t, "Update", anyContext, httpclient.Order{ID: "order-1", Status: "RECEIVED"})
+		})
+	}
+}
+
+func TestUseCases_ConfirmCashPayment_RetryAfterCoreFailure(t *testing.T) {
+	ctx := context.Background()
+	pending := entity.Payment{}.BuildCash("order-1", 25.25)
+
+	ds := &mockDataSource{}
+	orders := &mockOrderService{}
+	var saved dto.PaymentDAO
+	ds.On("FindByID", anyContext, pending.ID).Return(dto.ToPaymentDAO(pending), nil).Once()
+	ds.On("UpdatePending", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(dto.PaymentDAO)
+	}).Return(nil).Once()
+	orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{}, &apperror.ProviderUnavailableError{Provider: "core"}).Once()
+
+	useCases := Build(gateway.Build(ds), nil, nil, nil, orders, &mockPOSService{})
+
+	_, err := useCases.ConfirmCashPayment(ctx, pending.ID, 30, "cashier-1")
+	assert.IsType(t, &apperror.ProviderUnavailableError{}, err)
+	assert.Equal(t, enum.PaymentStatusApproved, saved.Status)
+
+	ds.On("FindByID", anyContext, pending.ID).Return(saved, nil).Once()
+	ds.On("Update", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(nil).Once()
+	orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1", Status: "PENDING"}, nil).Once()
+	orders.On("Update", anyContext, httpclient.Order{ID: "order-1", Status: "RECEIVED"}).Return(httpclient.Order{ID: "order-1", Status: "RECEIVED"}, nil).Once()
+
+	payment, err := useCases.ConfirmCashPayment(ctx, pending.ID, 50, "cashier-2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, enum.PaymentStatusApproved, payment.Status)
+	assert.Equal(t, "cashier-1", payment.Cash.CashierID, "the first confirmation should be kept")
+	assert.Equal(t, 4.75, payment.Cash.ChangeGiven)
+	orders.AssertCalled(t, "Update", anyContext, httpclient.Order{ID: "order-1", Status: "RECEIVED"})
+	ds.AssertNumberOfCalls(t, "UpdatePending", 1)
+}
+
+func TestUseCases_FindByID(t *testing.T) {
+	stored := entity.Payment{}.Build("order-1", "qr-data")
+
+	tests := []struct {
+		name        string
+		customerID  string
+		expectedErr error
+	}{
+		{
+			name: "Given no customer restriction, it should return the payment",
+		},
+		{
+			name:       "Given the order owner, it should return the payment",
+			customerID: "customer-1",
+		},
+		{
+			name:        "Given another customer, it should report not found",
+			customerID:  "customer-2",
+			expectedErr: &apperror.NotFoundError{Msg: "Payment not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ds := &mockDataSource{}
+			orders := &mockOrderService{}
+
+			ds.On("FindByID", anyContext, stored.ID).Return(dto.ToPaymentDAO(stored), nil)
+			orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1", CustomerID: "customer-1"}, nil)
+
+			useCases := Build(gateway.Build(ds), nil, nil, nil, orders, &mockPOSService{})
+
+			payment, err := useCases.FindByID(ctx, stored.ID, tt.customerID)
+
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, stored.ID, payment.ID)
+			if tt.customerID == "" {
+				orders.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+type mockPaymentMetrics struct {
+	mock.Mock
+}
+
+func (m *mockPaymentMetrics) PaymentCreated(method string, amount float64) {
+	m.Called(method, amount)
+}
+
+func (m *mockPaymentMetrics) PaymentApproved(method string, amount float64, timeToApproval time.Duration) {
+	m.Called(method, amount, timeToApproval)
+}
+
+func (m *mockPaymentMetrics) PaymentRejected(method string) {
+	m.Called(method)
+}
+
+func (m *mockPaymentMetrics) PaymentExpired(method string) {
+	m.Called(method)
+}
+
+func TestUseCases_CheckPayment(t *testing.T) {
+	pending := entity.Payment{}.Build("order-1", "qr-data")
+	pending.Amount = 25.25
+
+	approved := pending
+	approved.Status = enum.PaymentStatusApproved
+
+	tests := []struct {
+		name           string
+		stored         entity.Payment
+		orderStatus    string
+		updateErr      error
+		expectedStatus enum.PaymentStatus
+		expectedMetric string
+	}{
+		{
+			name:           "Given a paid order, it should approve the payment and record the approval",
+			stored:         pending,
+			orderStatus:    "paid",
+			expectedStatus: enum.PaymentStatusApproved,
+			expectedMetric: "PaymentApproved",
+		},
+		{
+			name:           "Given an expired order, it should expire the payment",
+			stored:         pending,
+			orderStatus:    "expired",
+			expectedStatus: enum.PaymentStatusExpired,
+			expectedMetric: "PaymentExpired",
+		},
+		{
+			name:           "Given a canceled order, it should reject the payment",
+			stored:         pending,
+			orderStatus:    "canceled",
+			expectedStatus: enum.PaymentStatusRejected,
+			expectedMetric: "PaymentRejected",
+		},
+		{
+			name:        "Given an order still in progress, it should leave the payment pending",
+			stored:      pending,
+			orderStatus: "payment_required",
+		},
+		{
+			name:        "Given a repeated paid notification, it should not count the approval twice",
+			stored:      approved,
+			orderStatus: "paid",
+		},
+		{
+			name:           "Given a notification settled concurrently by another delivery, it should succeed without counting it",
+			stored:         pending,
+			orderStatus:    "paid",
+			updateErr:      &apperror.ConflictError{Msg: "Payment is not pending"},
+			expectedStatus: enum.PaymentStatusApproved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ds := &mockDataSource{}
+			orders := &mockOrderService{}
+			provider := &external.MockQRCodeProvider{}
+			metrics := &mockPaymentMetrics{}
+
+			provider.On("CheckPayment", anyContext, "1").Return(dtos.ResponseVerifyOrderDTO{
+				ExternalReference: "order-1",
+				OrderStatus:       tt.orderStatus,
+			}, nil)
+			ds.On("FindByOrderID", anyContext, "order-1").Return(dto.ToPaymentDAO(tt.stored), nil)
+			ds.On("Update", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(nil)
+			ds.On("UpdatePending", anyContext, mock.AnythingOfType("dto.PaymentDAO")).Return(tt.updateErr)
+			orders.On("FindByID", anyContext, "order-1").Return(httpclient.Order{ID: "order-1"}, nil)
+			orders.On("Update", anyContext, mock.Anything).Return(httpclient.Order{ID: "order-1", Status: "RECEIVED"}, nil)
+			metrics.On("PaymentApproved", "QR_CODE", 25.25, mock.AnythingOfType("time.Duration")).Return()
+			metrics.On("PaymentExpired", "QR_CODE").Return()
+			metrics.On("PaymentRejected", "QR_CODE").Return()
+
+			useCases := Build(gateway.Build(ds), provider, nil, nil, orders, &mockPOSService{}, WithMetrics(metrics))
+
+			_, err := useCases.CheckPayment(ctx, "1")
+
+			assert.NoError(t, err)
+			if tt.expectedStatus != "" {
+				ds.AssertCalled(t, "UpdatePending", anyContext, mock.MatchedBy(func(dao dto.PaymentDAO) bool {
+					return dao.Status == tt.expectedStatus
+				}))
+			}
+			if tt.updateErr != nil {
+				orders.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+			for _, method := range []string{"PaymentApproved", "PaymentExpired", "PaymentRejected"} {
+				if method == tt.expectedMetric {
+					metrics.AssertNumberOfCalls(t, method, 1)
+				} else {
+					metrics.AssertNumberOfCalls(t, method, 0)
+				}
+			}
+			if tt.orderStatus != "paid" && tt.expectedStatus == "" {
+				ds.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				ds.AssertNotCalled(t, "UpdatePending", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUseCases_CheckPayment_MatchesProviderOrder(t *testing.T) {
+	expiredAttempt := entity.Payment{}.Build("order-1", "qr-data")
+	expiredAttempt.ProviderOrderID = "ORD-OLD"
+	expiredAttempt.Status = enum.PaymentStatusExpired
+
+	cash := entity.Payment{}.BuildCash("order-1", 25.25)
+
+	otherOrder := entity.Payment{}.Build("order-2", "qr-data")
+	otherOrder.ProviderOrderID = "ORD-OLD"
+
+	tests := []struct {
+		name     string
+		response dtos.ResponseVerifyOrderDTO
+		lookup   string
+		key      string
+		stored   entity.Payment
+	}{
+		{
+			name:     "Given a late paid notification for an expired attempt, it should not approve the newer payment",
+			response: dtos.ResponseVerifyOrderDTO{ExternalReference: "order-1", OrderStatus: "paid", ProviderOrderID: "ORD-OLD"},
+			lookup:   "FindByProviderOrderID",
+			key:      "ORD-OLD",
+			stored:   expiredAttempt,
+		},
+		{
+			name:     "Given a paid merchant order whose latest payment is cash, it should not settle it",
+			response: dtos.ResponseVerifyOrderDTO{ExternalReference: "order-1", OrderStatus: "paid"},
+			lookup:   "FindByOrderID",
+			key:      "order-1",
+			stored:   cash,
+		},
+		{
+			name:     "Given a provider order created for another order, it should not settle it",
+			response: dtos.ResponseVerifyOrderDTO{ExternalReference: "order-1", OrderStatus: "paid", ProviderOrderID: "ORD-OLD"},
+			lookup:   "FindByProviderOrderID",
+			key:      "ORD-OLD",
+			stored:   otherOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &mockDataSource{}
+			orders := &mockOrderService{}
+			provider := &external.MockQRCodeProvider{}
+
+			provider.On("CheckPayment", anyContext, "1").Return(tt.response, nil)
+			ds.On(tt.lookup, anyContext, tt.key).Return(dto.ToPaymentDAO(tt.stored), nil)
+			// storage refuses to approve a payment that is no longer pending;
+			// a call for any other payment fails the test
+			ds.On("UpdatePending", anyContext, mock.MatchedBy(func(dao dto.PaymentDAO) bool {
+				return dao.ID == tt.stored.ID
+			})).Return(&apperror.ConflictError{Msg: "Payment is not pending"})
+
+			useCases := Build(gateway.Build(ds), provider, nil, nil, orders, &mockPOSService{})
+
+			_, err := useCases.CheckPayment(context.Background(), "1")
+
+			assert.NoError(t, err)
+			if tt.stored.Status == enum.PaymentStatusPending {
+				ds.AssertNotCalled(t, "UpdatePending", mock.Anything, mock.Anything)
+			}
+			ds.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			orders.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			if tt.lookup == "FindByProviderOrderID" {
+				ds.AssertNotCalled(t, "FindByOrderID", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUseCases_CheckPayment_LinksToOriginatingTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	origin, originSpan := tracing.Start(context.Background(), "POST /payments")
+	originSpan.End()
+
+	stored := entity.Payment{}.Build("order-1", "qr-data")
+	stored.TraceID, stored.SpanID = tracing.IDs(origin)
+
+	ds := &mockDataSource{}
+	provider := &external.MockQRCodeProvider{}
+	provider.On("CheckPayment", anyContext, "1").Return(dtos.ResponseVerifyOrderDTO{
+		ExternalReference: "order-1",
+		OrderStatus:       "payment_required",
+	}, nil)
+	ds.On("FindByOrderID", anyContext, "order-1").Return(dto.ToPaymentDAO(stored), nil)
+
+	useCases := Build(gateway.Build(ds), provider, nil, nil, &mockOrderService{}, &mockPOSService{})
+
+	_, err := useCases.CheckPayment(context.Background(), "1")
+
+	assert.NoError(t, err)
+	var settle sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "payment.settle" {
+			settle = span
+		}
+	}
+	require.NotNil(t, settle)
+	require.Len(t, settle.Links(), 1)
+	assert.Equal(t, stored.TraceID, settle.Links()[0].SpanContext.TraceID().String())
+	assert.Equal(t, stored.SpanID, settle.Links()[0].SpanContext.SpanID().String())
+}
@@ -0,0 +1,22 @@
+package dtos
+
+// ResponseErrorDTO is the error body returned by Mercado Pago. The legacy
+// endpoints fill message/error/status/cause; the Orders API reports its
+// problems under errors.
+type ResponseErrorDTO struct {
+	Message string                  `json:"message"`
+	Error   string                  `json:"error"`
+	Status  FlexibleID              `json:"status"`
+	Cause   []ResponseErrorCauseDTO `json:"cause"`
+	Errors  []ResponseErrorItemDTO  `json:"errors"`
+}
+
+type ResponseErrorCauseDTO struct {
+	Code        FlexibleID `json:"code"`
+	Description string     `json:"description"`
+}
+
+type ResponseErrorItemDTO struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,34 @@
+package dtos
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// FlexibleID decodes Mercado Pago identifiers that come back either as JSON
+// numbers or as strings depending on the endpoint.
+type FlexibleID string
+
+func (f *FlexibleID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*f = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = FlexibleID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*f = FlexibleID(n.String())
+	return nil
+}
+
+func (f FlexibleID) String() string {
+	return string(f)
+}
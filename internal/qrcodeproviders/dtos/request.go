@@ -23,3 +23,41 @@ type RequestGenerateQRCodeItemDTO struct {
 func (r RequestGenerateQRCodeDTO) GetItems() []RequestGenerateQRCodeItemDTO {
 	return r.Items
 }
+
+// RequestCreateOrderDTO is the body of Mercado Pago's Orders API
+// (POST /v1/orders) for a dynamic QR order. Amounts are decimal strings.
+type RequestCreateOrderDTO struct {
+	Type              string                      `json:"type"`
+	ExternalReference string                      `json:"external_reference"`
+	Description       string                      `json:"description"`
+	TotalAmount       string                      `json:"total_amount"`
+	ExpirationTime    string                      `json:"expiration_time,omitempty"`
+	Config            RequestCreateOrderConfigDTO `json:"config"`
+	Transactions      RequestCreateOrderTxDTO     `json:"transactions"`
+	Items             []RequestCreateOrderItemDTO `json:"items"`
+}
+
+type RequestCreateOrderConfigDTO struct {
+	QR RequestCreateOrderQRConfigDTO `json:"qr"`
+}
+
+type RequestCreateOrderQRConfigDTO struct {
+	ExternalPosID string `json:"external_pos_id"`
+	Mode          string `json:"mode"`
+}
+
+type RequestCreateOrderTxDTO struct {
+	Payments []RequestCreateOrderPaymentDTO `json:"payments"`
+}
+
+type RequestCreateOrderPaymentDTO struct {
+	Amount string `json:"amount"`
+}
+
+type RequestCreateOrderItemDTO struct {
+	Title        string `json:"title"`
+	UnitPrice    string `json:"unit_price"`
+	Quantity     int    `json:"quantity"`
+	UnitMeasure  string `json:"unit_measure"`
+	ExternalCode string `json:"external_code,omitempty"`
+}
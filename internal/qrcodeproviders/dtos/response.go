@@ -8,4 +8,20 @@ type ResponseGenerateQRCodeDTO struct {
 type ResponseVerifyOrderDTO struct {
 	ExternalReference string `json:"external_reference"`
 	OrderStatus       string `json:"order_status"`
+	// ProviderOrderID is the Orders API order checked, matching the
+	// QRCode.ProviderOrderID it was created with. Legacy merchant orders
+	// leave it empty.
+	ProviderOrderID string `json:"-"`
+}
+
+type ResponseOrderDTO struct {
+	ID                string                       `json:"id"`
+	Status            string                       `json:"status"`
+	StatusDetail      string                       `json:"status_detail"`
+	ExternalReference string                       `json:"external_reference"`
+	TypeResponse      ResponseOrderTypeResponseDTO `json:"type_response"`
+}
+
+type ResponseOrderTypeResponseDTO struct {
+	QRData string `json:"qr_data"`
 }
@@ -0,0 +1,52 @@
+package dtos
+
+type RequestStoreDTO struct {
+	Name       string              `json:"name"`
+	ExternalID string              `json:"external_id,omitempty"`
+	Location   *RequestLocationDTO `json:"location,omitempty"`
+}
+
+type RequestLocationDTO struct {
+	StreetNumber string  `json:"street_number"`
+	StreetName   string  `json:"street_name"`
+	CityName     string  `json:"city_name"`
+	StateName    string  `json:"state_name"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	Reference    string  `json:"reference,omitempty"`
+}
+
+type ResponseStoreDTO struct {
+	ID         FlexibleID `json:"id"`
+	Name       string     `json:"name"`
+	ExternalID string     `json:"external_id"`
+}
+
+type ResponseStoreSearchDTO struct {
+	Results []ResponseStoreDTO `json:"results"`
+}
+
+type RequestPOSDTO struct {
+	Name            string `json:"name"`
+	FixedAmount     bool   `json:"fixed_amount"`
+	StoreID         string `json:"store_id,omitempty"`
+	ExternalStoreID string `json:"external_store_id,omitempty"`
+	ExternalID      string `json:"external_id,omitempty"`
+	Category        int    `json:"category,omitempty"`
+}
+
+type ResponsePOSDTO struct {
+	ID              FlexibleID       `json:"id"`
+	Name            string           `json:"name"`
+	ExternalID      string           `json:"external_id"`
+	ExternalStoreID string           `json:"external_store_id"`
+	QR              ResponsePOSQRDTO `json:"qr"`
+}
+
+type ResponsePOSQRDTO struct {
+	Image string `json:"image"`
+}
+
+type ResponsePOSSearchDTO struct {
+	Results []ResponsePOSDTO `json:"results"`
+}
@@ -2,7 +2,20 @@ package entities
 
 type GenerateQRCodeParams struct {
 	OrderID string
-	Items   []Item
+	// PaymentID identifies this payment attempt and keys the provider's
+	// idempotency, so retries of one attempt are deduplicated while a new
+	// payment for the same order gets a fresh QR code.
+	PaymentID string
+	Items     []Item
+	POS       POS
+}
+
+// POS addresses the Mercado Pago terminal that will show the QR code. Empty
+// fields fall back to the provider's default configuration.
+type POS struct {
+	CollectorUserID string
+	ExternalPosID   string
+	CredentialsRef  string
 }
 
 type Item struct {
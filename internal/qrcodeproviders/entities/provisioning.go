@@ -0,0 +1,46 @@
+package entities
+
+type ProvisionStoreParams struct {
+	CollectorUserID string
+	Name            string
+	ExternalID      string
+	// CredentialsRef names the collector token to provision with; empty
+	// uses the default access token.
+	CredentialsRef string
+	// Location is nil when it should not change, e.g. on a rename.
+	Location *Location
+}
+
+type Location struct {
+	StreetNumber string
+	StreetName   string
+	CityName     string
+	StateName    string
+	Latitude     float64
+	Longitude    float64
+	Reference    string
+}
+
+type ProvisionedStore struct {
+	ID         string
+	Name       string
+	ExternalID string
+}
+
+type ProvisionPOSParams struct {
+	Name            string
+	ProviderStoreID string
+	ExternalStoreID string
+	ExternalID      string
+	Category        int
+	// CredentialsRef names the token of the collector owning the store.
+	CredentialsRef string
+}
+
+type ProvisionedPOS struct {
+	ID              string
+	Name            string
+	ExternalID      string
+	ExternalStoreID string
+	QRImage         string
+}
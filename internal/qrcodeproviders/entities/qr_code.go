@@ -0,0 +1,9 @@
+package entities
+
+// QRCode is what a provider returns after generating a payment QR code.
+// ProviderOrderID identifies the order on the provider side so it can be
+// queried later.
+type QRCode struct {
+	ProviderOrderID string
+	QRData          string
+}
@@ -1,8 +1,16 @@
 package external
 
-import "github.com/go-resty/resty/v2"
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+)
 
 type MercadoPagoClient interface {
-	Get(url string, result interface{}) (*resty.Response, error)
-	Post(url string, body interface{}, result interface{}) (*resty.Response, error)
+	Get(ctx context.Context, url string, result interface{}) (*resty.Response, error)
+	GetWithHeaders(ctx context.Context, url string, headers map[string]string, result interface{}) (*resty.Response, error)
+	Post(ctx context.Context, url string, body interface{}, result interface{}) (*resty.Response, error)
+	PostWithHeaders(ctx context.Context, url string, headers map[string]string, body interface{}, result interface{}) (*resty.Response, error)
+	Put(ctx context.Context, url string, body interface{}, result interface{}) (*resty.Response, error)
+	PutWithHeaders(ctx context.Context, url string, headers map[string]string, body interface{}, result interface{}) (*resty.Response, error)
 }
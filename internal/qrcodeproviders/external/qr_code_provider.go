@@ -7,6 +7,6 @@ import (
 )
 
 type QRCodeProvider interface {
-	GenerateQRCode(ctx context.Context, request entities.GenerateQRCodeParams) (string, error)
-	CheckPayment(ctx context.Context, requestUrl string) (dtos.ResponseVerifyOrderDTO, error)
+	GenerateQRCode(ctx context.Context, request entities.GenerateQRCodeParams) (entities.QRCode, error)
+	CheckPayment(ctx context.Context, resourceID string) (dtos.ResponseVerifyOrderDTO, error)
 }
@@ -2,6 +2,7 @@ package external
 
 import (
 	"context"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
 
 	"github.com/stretchr/testify/mock"
@@ -11,12 +12,12 @@ type MockQRCodeProvider struct {
 	mock.Mock
 }
 
-func (m *MockQRCodeProvider) GenerateQRCode(ctx context.Context, request entities.GenerateQRCodeParams) (string, error) {
+func (m *MockQRCodeProvider) GenerateQRCode(ctx context.Context, request entities.GenerateQRCodeParams) (entities.QRCode, error) {
 	args := m.Called(ctx, request)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(entities.QRCode), args.Error(1)
 }
 
-func (m *MockQRCodeProvider) CheckPayment(ctx context.Context, requestUrl string) (any, error) {
-	args := m.Called(ctx, requestUrl)
-	return args.Get(0), args.Error(1)
+func (m *MockQRCodeProvider) CheckPayment(ctx context.Context, resourceID string) (dtos.ResponseVerifyOrderDTO, error) {
+	args := m.Called(ctx, resourceID)
+	return args.Get(0).(dtos.ResponseVerifyOrderDTO), args.Error(1)
 }
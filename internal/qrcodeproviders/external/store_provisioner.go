@@ -0,0 +1,19 @@
+package external
+
+import (
+	"context"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+)
+
+// StoreProvisioner manages stores and POS terminals on the provider side.
+// Each call authenticates as the collector its credentials ref names.
+type StoreProvisioner interface {
+	CreateStore(ctx context.Context, params entities.ProvisionStoreParams) (entities.ProvisionedStore, error)
+	UpdateStore(ctx context.Context, collectorUserID, providerStoreID string, params entities.ProvisionStoreParams) (entities.ProvisionedStore, error)
+	ListStores(ctx context.Context, collectorUserID, credentialsRef string) ([]entities.ProvisionedStore, error)
+
+	CreatePOS(ctx context.Context, params entities.ProvisionPOSParams) (entities.ProvisionedPOS, error)
+	UpdatePOS(ctx context.Context, providerPosID string, params entities.ProvisionPOSParams) (entities.ProvisionedPOS, error)
+	ListPOS(ctx context.Context, externalStoreID, credentialsRef string) ([]entities.ProvisionedPOS, error)
+}
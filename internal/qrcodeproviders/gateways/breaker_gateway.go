@@ -0,0 +1,35 @@
+package gateways
+
+import (
+	"context"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	external2 "github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+)
+
+// BreakerQRCodeProvider fails fast while the provider's breaker is open.
+type BreakerQRCodeProvider struct {
+	next    external2.QRCodeProvider
+	breaker *circuitbreaker.Breaker
+}
+
+func WithBreaker(next external2.QRCodeProvider, breaker *circuitbreaker.Breaker) external2.QRCodeProvider {
+	return &BreakerQRCodeProvider{
+		next:    next,
+		breaker: breaker,
+	}
+}
+
+func (b *BreakerQRCodeProvider) GenerateQRCode(ctx context.Context, request entities.GenerateQRCodeParams) (entities.QRCode, error) {
+	return circuitbreaker.Call(b.breaker, func() (entities.QRCode, error) {
+		return b.next.GenerateQRCode(ctx, request)
+	})
+}
+
+func (b *BreakerQRCodeProvider) CheckPayment(ctx context.Context, resourceID string) (dtos.ResponseVerifyOrderDTO, error) {
+	return circuitbreaker.Call(b.breaker, func() (dtos.ResponseVerifyOrderDTO, error) {
+		return b.next.CheckPayment(ctx, resourceID)
+	})
+}
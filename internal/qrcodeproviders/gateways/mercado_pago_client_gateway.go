@@ -1,18 +1,189 @@
 package gateways
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/logger"
+)
+
+const (
+	providerName         = "mercadopago"
+	idempotencyKeyHeader = "X-Idempotency-Key"
 )
 
 type MercadoPagoClientRest struct {
 	client *resty.Client
 }
 
-func (r *MercadoPagoClientRest) Get(url string, result interface{}) (*resty.Response, error) {
+// RetryPolicy controls how calls to Mercado Pago are retried. Timeout
+// applies to each attempt; the caller's context bounds the whole call.
+type RetryPolicy struct {
+	Timeout     time.Duration
+	Retries     int
+	WaitTime    time.Duration
+	MaxWaitTime time.Duration
+}
+
+// withRetryPolicy configures per-attempt timeouts and jittered exponential
+// backoff. Only idempotent calls are retried, and only on transport errors,
+// 5xx and 429; a Retry-After header takes precedence over the backoff.
+func withRetryPolicy(client *resty.Client, policy RetryPolicy) *resty.Client {
+	return client.
+		SetTimeout(policy.Timeout).
+		SetRetryCount(policy.Retries).
+		SetRetryWaitTime(policy.WaitTime).
+		SetRetryMaxWaitTime(policy.MaxWaitTime).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(shouldRetry)
+}
+
+func shouldRetry(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || !isIdempotent(resp.Request) {
+		return false
+	}
+	if err != nil {
+		return resp.Request.Context().Err() == nil
+	}
+	return isUnavailableStatus(resp.StatusCode())
+}
+
+func isIdempotent(req *resty.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(idempotencyKeyHeader) != ""
+}
+
+func isUnavailableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryAfter reads Retry-After as seconds or an HTTP date. Returning zero
+// lets resty fall back to its jittered backoff.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	header := resp.Header().Get("Retry-After")
+	if header == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, nil
+		}
+	}
+	return 0, nil
+}
+
+// providerError turns a failed call into an error. Timeouts, transport
+// failures, 5xx and 429 become ProviderUnavailableError; other HTTP errors
+// become a ProviderError parsed from Mercado Pago's error body.
+func providerError(res *resty.Response, reqErr error) error {
+	if res != nil && res.StatusCode() != 0 {
+		if isUnavailableStatus(res.StatusCode()) {
+			err := &apperror.ProviderUnavailableError{
+				Provider: providerName,
+				Msg:      fmt.Sprintf("status %d calling %s", res.StatusCode(), res.Request.URL),
+			}
+			logProviderFailure(res, err)
+			return err
+		}
+		if res.IsError() {
+			err := parseProviderError(res)
+			logProviderFailure(res, err)
+			return err
+		}
+		return reqErr
+	}
+
+	if reqErr != nil {
+		err := &apperror.ProviderUnavailableError{
+			Provider: providerName,
+			Msg:      reqErr.Error(),
+		}
+		logProviderFailure(res, err)
+		return err
+	}
+	return nil
+}
+
+// parseProviderError reads Mercado Pago's error body. Bodies that are not
+// JSON are kept as the message so nothing the provider said is lost.
+func parseProviderError(res *resty.Response) *apperror.ProviderError {
+	providerErr := &apperror.ProviderError{
+		Provider:   providerName,
+		StatusCode: res.StatusCode(),
+	}
+
+	var body dtos.ResponseErrorDTO
+	if err := json.Unmarshal(res.Body(), &body); err != nil {
+		providerErr.Msg = strings.TrimSpace(string(res.Body()))
+		if providerErr.Msg == "" {
+			providerErr.Msg = http.StatusText(res.StatusCode())
+		}
+		return providerErr
+	}
+
+	providerErr.Code = body.Error
+	providerErr.Msg = body.Message
+	for _, cause := range body.Cause {
+		providerErr.Causes = append(providerErr.Causes, apperror.ProviderErrorCause{
+			Code:        cause.Code.String(),
+			Description: cause.Description,
+		})
+	}
+	for _, item := range body.Errors {
+		providerErr.Causes = append(providerErr.Causes, apperror.ProviderErrorCause{
+			Code:        item.Code,
+			Description: item.Message,
+		})
+	}
+	if providerErr.Msg == "" {
+		providerErr.Msg = http.StatusText(res.StatusCode())
+	}
+	return providerErr
+}
+
+func logProviderFailure(res *resty.Response, err error) {
+	ctx := context.Background()
+	attrs := []any{"provider", providerName, "error", err.Error()}
+	if res != nil && res.Request != nil {
+		ctx = res.Request.Context()
+		attrs = append(attrs, "method", res.Request.Method, "url", res.Request.URL, "status", res.StatusCode())
+	}
+	slog.WarnContext(ctx, "mercado pago request failed", attrs...)
+}
+
+// propagateRequestID sends the request ID along so Mercado Pago calls can be
+// correlated with our logs.
+func propagateRequestID(_ *resty.Client, req *resty.Request) error {
+	if id := logger.RequestID(req.Context()); id != "" {
+		req.SetHeader(logger.RequestIDHeader, id)
+	}
+	return nil
+}
+
+func (r *MercadoPagoClientRest) Get(ctx context.Context, url string, result interface{}) (*resty.Response, error) {
+	return r.GetWithHeaders(ctx, url, nil, result)
+}
+
+func (r *MercadoPagoClientRest) GetWithHeaders(ctx context.Context, url string, headers map[string]string, result interface{}) (*resty.Response, error) {
 	resp, err := r.client.R().
+		SetContext(ctx).
+		SetHeaders(headers).
 		SetResult(result).
 		Get(url)
 
@@ -27,8 +198,14 @@ func (r *MercadoPagoClientRest) Get(url string, result interface{}) (*resty.Resp
 	return resp, nil
 }
 
-func (r *MercadoPagoClientRest) Post(url string, body interface{}, result interface{}) (*resty.Response, error) {
+func (r *MercadoPagoClientRest) Post(ctx context.Context, url string, body interface{}, result interface{}) (*resty.Response, error) {
+	return r.PostWithHeaders(ctx, url, nil, body, result)
+}
+
+func (r *MercadoPagoClientRest) PostWithHeaders(ctx context.Context, url string, headers map[string]string, body interface{}, result interface{}) (*resty.Response, error) {
 	resp, err := r.client.R().
+		SetContext(ctx).
+		SetHeaders(headers).
 		SetBody(body).
 		Post(url)
 
@@ -42,3 +219,25 @@ func (r *MercadoPagoClientRest) Post(url string, body interface{}, result interf
 
 	return resp, nil
 }
+
+func (r *MercadoPagoClientRest) Put(ctx context.Context, url string, body interface{}, result interface{}) (*resty.Response, error) {
+	return r.PutWithHeaders(ctx, url, nil, body, result)
+}
+
+func (r *MercadoPagoClientRest) PutWithHeaders(ctx context.Context, url string, headers map[string]string, body interface{}, result interface{}) (*resty.Response, error) {
+	resp, err := r.client.R().
+		SetContext(ctx).
+		SetHeaders(headers).
+		SetBody(body).
+		Put(url)
+
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return resp, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp, nil
+}
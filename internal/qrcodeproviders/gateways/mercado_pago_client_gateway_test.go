@@ -1,12 +1,16 @@
 package gateways
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
 )
 
 type mockResponse struct {
@@ -50,7 +54,7 @@ func TestMercadoPagoClientRest_Get(t *testing.T) {
 			}
 
 			var resp mockResponse
-			_, err := client.Get("/", &resp)
+			_, err := client.Get(context.Background(), "/", &resp)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -100,7 +104,7 @@ func TestMercadoPagoClientRest_Post(t *testing.T) {
 			}
 
 			var result mockResponse
-			_, err := client.Post("/", map[string]string{"input": "value"}, &result)
+			_, err := client.Post(context.Background(), "/", map[string]string{"input": "value"}, &result)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -111,3 +115,166 @@ func TestMercadoPagoClientRest_Post(t *testing.T) {
 		})
 	}
 }
+
+func TestMercadoPagoClientRest_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		idempotencyKey   string
+		responses        []int
+		expectedAttempts int
+		expectError      bool
+	}{
+		{
+			name:             "GET is retried on 503 until it succeeds",
+			method:           http.MethodGet,
+			responses:        []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "GET is not retried on 400",
+			method:           http.MethodGet,
+			responses:        []int{http.StatusBadRequest},
+			expectedAttempts: 1,
+			expectError:      true,
+		},
+		{
+			name:             "POST without idempotency key is not retried",
+			method:           http.MethodPost,
+			responses:        []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedAttempts: 1,
+			expectError:      true,
+		},
+		{
+			name:             "POST with idempotency key is retried",
+			method:           http.MethodPost,
+			idempotencyKey:   "order-1",
+			responses:        []int{http.StatusBadGateway, http.StatusOK},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "GET gives up after the configured retries",
+			method:           http.MethodGet,
+			responses:        []int{503, 503, 503, 503},
+			expectedAttempts: 3,
+			expectError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.responses[attempts]
+				attempts++
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{"message":"ok"}`))
+			}))
+			defer server.Close()
+
+			client := &MercadoPagoClientRest{
+				client: withRetryPolicy(resty.New().SetBaseURL(server.URL), RetryPolicy{
+					Timeout:     time.Second,
+					Retries:     2,
+					WaitTime:    time.Millisecond,
+					MaxWaitTime: 5 * time.Millisecond,
+				}),
+			}
+
+			var result mockResponse
+			var res *resty.Response
+			var err error
+			if tt.method == http.MethodGet {
+				res, err = client.Get(context.Background(), "/", &result)
+			} else {
+				headers := map[string]string{}
+				if tt.idempotencyKey != "" {
+					headers[idempotencyKeyHeader] = tt.idempotencyKey
+				}
+				res, err = client.PostWithHeaders(context.Background(), "/", headers, map[string]string{}, &result)
+			}
+
+			assert.Equal(t, tt.expectedAttempts, attempts)
+			if tt.expectError {
+				assert.Error(t, providerError(res, err))
+			} else {
+				assert.NoError(t, providerError(res, err))
+			}
+		})
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := &MercadoPagoClientRest{
+		client: withRetryPolicy(resty.New().SetBaseURL(server.URL), RetryPolicy{Timeout: 10 * time.Millisecond}),
+	}
+
+	var result mockResponse
+	res, err := client.Get(context.Background(), "/", &result)
+
+	var unavailableErr *apperror.ProviderUnavailableError
+	assert.ErrorAs(t, providerError(res, err), &unavailableErr)
+}
+
+func TestProviderError_ParsesErrorBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		expected     *apperror.ProviderError
+	}{
+		{
+			name:         "legacy error body",
+			responseBody: `{"message":"invalid external_pos_id","error":"bad_request","status":400,"cause":[{"code":2001,"description":"pos not found"}]}`,
+			expected: &apperror.ProviderError{
+				Provider:   providerName,
+				StatusCode: http.StatusBadRequest,
+				Code:       "bad_request",
+				Msg:        "invalid external_pos_id",
+				Causes:     []apperror.ProviderErrorCause{{Code: "2001", Description: "pos not found"}},
+			},
+		},
+		{
+			name:         "orders api error body",
+			responseBody: `{"errors":[{"code":"invalid_total_amount","message":"total_amount must be positive"}]}`,
+			expected: &apperror.ProviderError{
+				Provider:   providerName,
+				StatusCode: http.StatusBadRequest,
+				Msg:        "Bad Request",
+				Causes:     []apperror.ProviderErrorCause{{Code: "invalid_total_amount", Description: "total_amount must be positive"}},
+			},
+		},
+		{
+			name:         "non json body",
+			responseBody: "bad things happened",
+			expected: &apperror.ProviderError{
+				Provider:   providerName,
+				StatusCode: http.StatusBadRequest,
+				Msg:        "bad things happened",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			res, err := resty.New().SetBaseURL(server.URL).R().Get("/")
+
+			var providerErr *apperror.ProviderError
+			assert.ErrorAs(t, providerError(res, err), &providerErr)
+			assert.Equal(t, tt.expected, providerErr)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package gateways
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	external2 "github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/presenters"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/config"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+// orderStatusProcessed is the Orders API status of a fully paid order.
+const orderStatusProcessed = "processed"
+
+// MercadoPagoOrdersClient generates dynamic QR codes through Mercado Pago's
+// Orders API. Unlike the legacy instore endpoint, each call creates an
+// independent order, so several totems can share a POS with concurrent
+// open orders.
+type MercadoPagoOrdersClient struct {
+	client          external2.MercadoPagoClient
+	config          config.MercadoPago
+	collectorTokens secrets.Set
+}
+
+func (m *MercadoPagoOrdersClient) GenerateQRCode(ctx context.Context, params entities.GenerateQRCodeParams) (entities.QRCode, error) {
+	pos, err := resolvePOS(m.config, params.POS)
+	if err != nil {
+		return entities.QRCode{}, err
+	}
+	requestBody := presenters.OrderRequestBodyFromParams(
+		params,
+		pos.ExternalPosID,
+		m.config.Orders.Expiration,
+	)
+	headers, err := credentialHeaders(m.collectorTokens, pos)
+	if err != nil {
+		return entities.QRCode{}, err
+	}
+	headers[idempotencyKeyHeader] = params.PaymentID
+
+	var responseDTO dtos.ResponseOrderDTO
+	res, reqErr := m.client.PostWithHeaders(ctx, m.config.Orders.Path, headers, requestBody, &responseDTO)
+
+	if err := providerError(res, reqErr); err != nil {
+		return entities.QRCode{}, err
+	}
+
+	return entities.QRCode{
+		ProviderOrderID: responseDTO.ID,
+		QRData:          responseDTO.TypeResponse.QRData,
+	}, nil
+}
+
+// CheckPayment looks up the order a notification names by its ID on the
+// configured host, and reports the order as "paid" once it is processed.
+func (m *MercadoPagoOrdersClient) CheckPayment(ctx context.Context, orderID string) (dtos.ResponseVerifyOrderDTO, error) {
+	requestPath := strings.TrimSuffix(m.config.Orders.Path, "/") + "/" + url.PathEscape(orderID)
+
+	var responseDTO dtos.ResponseOrderDTO
+	res, reqErr := m.client.Get(ctx, requestPath, &responseDTO)
+
+	if err := providerError(res, reqErr); err != nil {
+		return dtos.ResponseVerifyOrderDTO{}, err
+	}
+
+	orderStatus := responseDTO.Status
+	if orderStatus == orderStatusProcessed {
+		orderStatus = "paid"
+	}
+
+	return dtos.ResponseVerifyOrderDTO{
+		ExternalReference: responseDTO.ExternalReference,
+		OrderStatus:       orderStatus,
+		ProviderOrderID:   responseDTO.ID,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package gateways
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+)
+
+func newMockedMercadoPagoOrdersClient(baseURL string) *MercadoPagoOrdersClient {
+	return &MercadoPagoOrdersClient{
+		client: &MercadoPagoClientRest{
+			resty.New().
+				SetBaseURL(baseURL).
+				SetHeader("Content-Type", "application/json"),
+		},
+		config: testConfig(),
+	}
+}
+
+func TestMercadoPagoOrdersClient_GenerateQRCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		responseBody string
+		expectError  bool
+		expectedQR   entities.QRCode
+	}{
+		{
+			name:       "success",
+			statusCode: http.StatusCreated,
+			responseBody: `{
+				"id": "ORD01JQ4S4KY8HWQ6NA5PXB65B3D3",
+				"status": "created",
+				"type_response": {"qr_data": "00020101021243650016COM.MERCADOLIBRE"}
+			}`,
+			expectedQR: entities.QRCode{
+				ProviderOrderID: "ORD01JQ4S4KY8HWQ6NA5PXB65B3D3",
+				QRData:          "00020101021243650016COM.MERCADOLIBRE",
+			},
+		},
+		{
+			name:         "error status code",
+			statusCode:   http.StatusBadRequest,
+			responseBody: `{"errors": [{"code": "invalid_pos"}]}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/orders", r.URL.Path)
+				assert.Equal(t, "paymentId", r.Header.Get("X-Idempotency-Key"))
+
+				var body dtos.RequestCreateOrderDTO
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "posid_34", body.Config.QR.ExternalPosID)
+				assert.Equal(t, "dynamic", body.Config.QR.Mode)
+				assert.Equal(t, "24.60", body.TotalAmount)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			qrCode, err := newMockedMercadoPagoOrdersClient(server.URL).GenerateQRCode(context.Background(), entities.GenerateQRCodeParams{
+				OrderID:   "orderId",
+				PaymentID: "paymentId",
+				Items: []entities.Item{
+					{ID: "itemId", Name: "itemName", Price: 12.3, Quantity: 2, Amount: 24.6},
+				},
+			})
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Empty(t, qrCode)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedQR, qrCode)
+			}
+		})
+	}
+}
+
+func TestMercadoPagoOrdersClient_CheckPayment(t *testing.T) {
+	tests := []struct {
+		name                string
+		resource            string
+		responseBody        string
+		expectedOrderStatus string
+	}{
+		{
+			name:                "Given a processed order ID, it should report it as paid",
+			resource:            "ORD123",
+			responseBody:        `{"id": "ORD123", "status": "processed", "external_reference": "orderId12"}`,
+			expectedOrderStatus: "paid",
+		},
+		{
+			name:                "Given an order in progress, it should pass the status through",
+			resource:            "ORD123",
+			responseBody:        `{"id": "ORD123", "status": "created", "external_reference": "orderId12"}`,
+			expectedOrderStatus: "created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTestServer(t, "/v1/orders/ORD123", http.StatusOK, tt.responseBody)
+			defer server.Close()
+
+			resp, err := newMockedMercadoPagoOrdersClient(server.URL).CheckPayment(context.Background(), tt.resource)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "orderId12", resp.ExternalReference)
+			assert.Equal(t, tt.expectedOrderStatus, resp.OrderStatus)
+			assert.Equal(t, "ORD123", resp.ProviderOrderID)
+		})
+	}
+}
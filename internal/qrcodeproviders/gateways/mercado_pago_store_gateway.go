@@ -0,0 +1,220 @@
+package gateways
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	external2 "github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/config"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+// posCategoryFastFood is Mercado Pago's MCC category for fast food restaurants.
+const posCategoryFastFood = 5814
+
+// MercadoPagoStoreClient provisions stores and POS terminals through
+// Mercado Pago's /users/{user_id}/stores and /pos APIs. Stores and POS of
+// a collector with its own credentials are managed with that collector's
+// token, the same one its payments use.
+type MercadoPagoStoreClient struct {
+	client          external2.MercadoPagoClient
+	config          config.MercadoPago
+	collectorTokens secrets.Set
+}
+
+func NewStoreProvisioner(cfg config.MercadoPago, opts ...Option) external2.StoreProvisioner {
+	o := buildOptions(opts)
+	return &MercadoPagoStoreClient{
+		client:          getClient(cfg, o.client...),
+		config:          cfg,
+		collectorTokens: o.collectorTokens,
+	}
+}
+
+func (m *MercadoPagoStoreClient) CreateStore(ctx context.Context, params entities.ProvisionStoreParams) (entities.ProvisionedStore, error) {
+	resolvedPath, err := shared.BuildPath(m.config.Stores.Path, []shared.BuildPathParam{
+		{Key: "user_id", Value: params.CollectorUserID},
+	})
+	if err != nil {
+		return entities.ProvisionedStore{}, err
+	}
+
+	headers, err := collectorHeaders(m.collectorTokens, params.CredentialsRef)
+	if err != nil {
+		return entities.ProvisionedStore{}, err
+	}
+
+	var responseDTO dtos.ResponseStoreDTO
+	res, reqErr := m.client.PostWithHeaders(ctx, resolvedPath, headers, storeRequestFromParams(params), &responseDTO)
+	if err := providerError(res, reqErr); err != nil {
+		return entities.ProvisionedStore{}, err
+	}
+
+	return storeFromResponse(responseDTO), nil
+}
+
+func (m *MercadoPagoStoreClient) UpdateStore(ctx context.Context, collectorUserID, providerStoreID string, params entities.ProvisionStoreParams) (entities.ProvisionedStore, error) {
+	resolvedPath, err := shared.BuildPath(m.config.Stores.Path, []shared.BuildPathParam{
+		{Key: "user_id", Value: collectorUserID},
+	})
+	if err != nil {
+		return entities.ProvisionedStore{}, err
+	}
+
+	headers, err := collectorHeaders(m.collectorTokens, params.CredentialsRef)
+	if err != nil {
+		return entities.ProvisionedStore{}, err
+	}
+
+	var responseDTO dtos.ResponseStoreDTO
+	res, reqErr := m.client.PutWithHeaders(ctx, resolvedPath+"/"+url.PathEscape(providerStoreID), headers, storeRequestFromParams(params), &responseDTO)
+	if err := providerError(res, reqErr); err != nil {
+		return entities.ProvisionedStore{}, err
+	}
+
+	return storeFromResponse(responseDTO), nil
+}
+
+func (m *MercadoPagoStoreClient) ListStores(ctx context.Context, collectorUserID, credentialsRef string) ([]entities.ProvisionedStore, error) {
+	resolvedPath, err := shared.BuildPath(m.config.Stores.SearchPath, []shared.BuildPathParam{
+		{Key: "user_id", Value: collectorUserID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := collectorHeaders(m.collectorTokens, credentialsRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var responseDTO dtos.ResponseStoreSearchDTO
+	res, reqErr := m.client.GetWithHeaders(ctx, resolvedPath, headers, &responseDTO)
+	if err := providerError(res, reqErr); err != nil {
+		return nil, err
+	}
+
+	stores := make([]entities.ProvisionedStore, 0, len(responseDTO.Results))
+	for _, store := range responseDTO.Results {
+		stores = append(stores, storeFromResponse(store))
+	}
+	return stores, nil
+}
+
+func (m *MercadoPagoStoreClient) CreatePOS(ctx context.Context, params entities.ProvisionPOSParams) (entities.ProvisionedPOS, error) {
+	headers, err := collectorHeaders(m.collectorTokens, params.CredentialsRef)
+	if err != nil {
+		return entities.ProvisionedPOS{}, err
+	}
+
+	var responseDTO dtos.ResponsePOSDTO
+	res, reqErr := m.client.PostWithHeaders(ctx, m.config.POS.Path, headers, posRequestFromParams(params), &responseDTO)
+	if err := providerError(res, reqErr); err != nil {
+		return entities.ProvisionedPOS{}, err
+	}
+
+	return posFromResponse(responseDTO), nil
+}
+
+func (m *MercadoPagoStoreClient) UpdatePOS(ctx context.Context, providerPosID string, params entities.ProvisionPOSParams) (entities.ProvisionedPOS, error) {
+	headers, err := collectorHeaders(m.collectorTokens, params.CredentialsRef)
+	if err != nil {
+		return entities.ProvisionedPOS{}, err
+	}
+
+	requestBody := dtos.RequestPOSDTO{
+		Name:        params.Name,
+		FixedAmount: true,
+		Category:    params.Category,
+	}
+
+	var responseDTO dtos.ResponsePOSDTO
+	res, reqErr := m.client.PutWithHeaders(ctx, m.config.POS.Path+"/"+url.PathEscape(providerPosID), headers, requestBody, &responseDTO)
+	if err := providerError(res, reqErr); err != nil {
+		return entities.ProvisionedPOS{}, err
+	}
+
+	return posFromResponse(responseDTO), nil
+}
+
+func (m *MercadoPagoStoreClient) ListPOS(ctx context.Context, externalStoreID, credentialsRef string) ([]entities.ProvisionedPOS, error) {
+	headers, err := collectorHeaders(m.collectorTokens, credentialsRef)
+	if err != nil {
+		return nil, err
+	}
+
+	requestUrl := m.config.POS.Path
+	if externalStoreID != "" {
+		requestUrl += "?external_store_id=" + url.QueryEscape(externalStoreID)
+	}
+
+	var responseDTO dtos.ResponsePOSSearchDTO
+	res, reqErr := m.client.GetWithHeaders(ctx, requestUrl, headers, &responseDTO)
+	if err := providerError(res, reqErr); err != nil {
+		return nil, err
+	}
+
+	pos := make([]entities.ProvisionedPOS, 0, len(responseDTO.Results))
+	for _, p := range responseDTO.Results {
+		pos = append(pos, posFromResponse(p))
+	}
+	return pos, nil
+}
+
+// storeRequestFromParams leaves the location out when it is not set, so an
+// update does not overwrite the store's address with blanks.
+func storeRequestFromParams(params entities.ProvisionStoreParams) dtos.RequestStoreDTO {
+	request := dtos.RequestStoreDTO{
+		Name:       params.Name,
+		ExternalID: params.ExternalID,
+	}
+	if location := params.Location; location != nil {
+		request.Location = &dtos.RequestLocationDTO{
+			StreetNumber: location.StreetNumber,
+			StreetName:   location.StreetName,
+			CityName:     location.CityName,
+			StateName:    location.StateName,
+			Latitude:     location.Latitude,
+			Longitude:    location.Longitude,
+			Reference:    location.Reference,
+		}
+	}
+	return request
+}
+
+func posRequestFromParams(params entities.ProvisionPOSParams) dtos.RequestPOSDTO {
+	category := params.Category
+	if category == 0 {
+		category = posCategoryFastFood
+	}
+
+	return dtos.RequestPOSDTO{
+		Name:            params.Name,
+		FixedAmount:     true,
+		StoreID:         params.ProviderStoreID,
+		ExternalStoreID: params.ExternalStoreID,
+		ExternalID:      params.ExternalID,
+		Category:        category,
+	}
+}
+
+func storeFromResponse(responseDTO dtos.ResponseStoreDTO) entities.ProvisionedStore {
+	return entities.ProvisionedStore{
+		ID:         responseDTO.ID.String(),
+		Name:       responseDTO.Name,
+		ExternalID: responseDTO.ExternalID,
+	}
+}
+
+func posFromResponse(responseDTO dtos.ResponsePOSDTO) entities.ProvisionedPOS {
+	return entities.ProvisionedPOS{
+		ID:              responseDTO.ID.String(),
+		Name:            responseDTO.Name,
+		ExternalID:      responseDTO.ExternalID,
+		ExternalStoreID: responseDTO.ExternalStoreID,
+		QRImage:         responseDTO.QR.Image,
+	}
+}
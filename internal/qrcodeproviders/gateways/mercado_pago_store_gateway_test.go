@@ -0,0 +1,195 @@
+package gateways
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+)
+
+func newMockedMercadoPagoStoreClient(baseURL string) *MercadoPagoStoreClient {
+	return &MercadoPagoStoreClient{
+		client:          &MercadoPagoClientRest{resty.New().SetBaseURL(baseURL).SetHeader("Authorization", "Bearer dummy")},
+		config:          testConfig(),
+		collectorTokens: testCollectorTokens(),
+	}
+}
+
+func TestMercadoPagoStoreClient_CreateStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/users/collector_1/stores", r.URL.Path)
+
+		var body dtos.RequestStoreDTO
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "STORE001", body.ExternalID)
+		assert.Equal(t, "São Paulo", body.Location.CityName)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1234567, "name": "GoLunch Paulista", "external_id": "STORE001"}`))
+	}))
+	defer server.Close()
+
+	store, err := newMockedMercadoPagoStoreClient(server.URL).CreateStore(context.Background(), entities.ProvisionStoreParams{
+		CollectorUserID: "collector_1",
+		Name:            "GoLunch Paulista",
+		ExternalID:      "STORE001",
+		Location:        &entities.Location{CityName: "São Paulo"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ProvisionedStore{ID: "1234567", Name: "GoLunch Paulista", ExternalID: "STORE001"}, store)
+}
+
+func TestMercadoPagoStoreClient_UpdateStore(t *testing.T) {
+	tests := []struct {
+		name             string
+		location         *entities.Location
+		expectedLocation *dtos.RequestLocationDTO
+	}{
+		{
+			name: "Given no location, it should leave it out of the request",
+		},
+		{
+			name:             "Given a location, it should send it",
+			location:         &entities.Location{StreetName: "Av. Paulista", CityName: "São Paulo"},
+			expectedLocation: &dtos.RequestLocationDTO{StreetName: "Av. Paulista", CityName: "São Paulo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.Equal(t, "/users/collector_1/stores/1234567", r.URL.Path)
+
+				var body map[string]json.RawMessage
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				raw, sent := body["location"]
+				assert.Equal(t, tt.expectedLocation != nil, sent)
+				if tt.expectedLocation != nil {
+					var location dtos.RequestLocationDTO
+					assert.NoError(t, json.Unmarshal(raw, &location))
+					assert.Equal(t, *tt.expectedLocation, location)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id": 1234567, "name": "GoLunch Paulista", "external_id": "STORE001"}`))
+			}))
+			defer server.Close()
+
+			_, err := newMockedMercadoPagoStoreClient(server.URL).UpdateStore(context.Background(), "collector_1", "1234567", entities.ProvisionStoreParams{
+				Name:       "GoLunch Paulista",
+				ExternalID: "STORE001",
+				Location:   tt.location,
+			})
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMercadoPagoStoreClient_CreatePOS(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		responseBody string
+		expectError  bool
+		expected     entities.ProvisionedPOS
+	}{
+		{
+			name:         "success",
+			statusCode:   http.StatusCreated,
+			responseBody: `{"id": 987, "name": "Totem 1", "external_id": "STORE001POS001", "external_store_id": "STORE001", "qr": {"image": "https://qr"}}`,
+			expected: entities.ProvisionedPOS{
+				ID:              "987",
+				Name:            "Totem 1",
+				ExternalID:      "STORE001POS001",
+				ExternalStoreID: "STORE001",
+				QRImage:         "https://qr",
+			},
+		},
+		{
+			name:         "error status code",
+			statusCode:   http.StatusBadRequest,
+			responseBody: `{"message": "external_id already exists"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body dtos.RequestPOSDTO
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.True(t, body.FixedAmount)
+				assert.Equal(t, posCategoryFastFood, body.Category)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			pos, err := newMockedMercadoPagoStoreClient(server.URL).CreatePOS(context.Background(), entities.ProvisionPOSParams{
+				Name:            "Totem 1",
+				ProviderStoreID: "1234567",
+				ExternalStoreID: "STORE001",
+				ExternalID:      "STORE001POS001",
+			})
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, pos)
+		})
+	}
+}
+
+func TestMercadoPagoStoreClient_CredentialsRef(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "results": []}`))
+	}))
+	defer server.Close()
+
+	client := newMockedMercadoPagoStoreClient(server.URL)
+	ctx := context.Background()
+
+	_, err := client.CreateStore(ctx, entities.ProvisionStoreParams{CollectorUserID: "collector_2", CredentialsRef: "STORE2"})
+	assert.NoError(t, err)
+	_, err = client.UpdateStore(ctx, "collector_2", "1", entities.ProvisionStoreParams{CredentialsRef: "store2"})
+	assert.NoError(t, err)
+	_, err = client.ListStores(ctx, "collector_2", "store2")
+	assert.NoError(t, err)
+	_, err = client.CreatePOS(ctx, entities.ProvisionPOSParams{CredentialsRef: "store2"})
+	assert.NoError(t, err)
+	_, err = client.UpdatePOS(ctx, "1", entities.ProvisionPOSParams{CredentialsRef: "store2"})
+	assert.NoError(t, err)
+	_, err = client.ListPOS(ctx, "STORE2", "store2")
+	assert.NoError(t, err)
+	_, err = client.ListPOS(ctx, "STORE1", "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"Bearer store2-token", "Bearer store2-token", "Bearer store2-token",
+		"Bearer store2-token", "Bearer store2-token", "Bearer store2-token",
+		"Bearer dummy",
+	}, authorizations, "a credentials ref should select the collector token, and none the default one")
+
+	_, err = client.CreateStore(ctx, entities.ProvisionStoreParams{CollectorUserID: "collector_3", CredentialsRef: "store3"})
+	assert.ErrorContains(t, err, "unknown credentials_ref")
+	assert.Len(t, authorizations, 7, "an unknown ref should not reach Mercado Pago")
+}
@@ -2,9 +2,10 @@ package gateways
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"os"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
@@ -12,91 +13,212 @@ import (
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/presenters"
 
 	"github.com/go-resty/resty/v2"
-	"github.com/spf13/viper"
 
 	"github.com/fiap-161/tc-golunch-payment-service/internal/shared"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/config"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
 )
 
-func GetSellerUserID() string {
-	return os.Getenv("MERCADO_PAGO_SELLER_APP_USER_ID")
+// resolvePOS fills the POS fields missing from the request with the
+// deployment-wide defaults. A payment naming no POS is a ValidationError
+// when the deployment has no default POS.
+func resolvePOS(cfg config.MercadoPago, pos entities.POS) (entities.POS, error) {
+	if pos.CollectorUserID == "" {
+		pos.CollectorUserID = cfg.SellerUserID
+	}
+	if pos.ExternalPosID == "" {
+		pos.ExternalPosID = cfg.ExternalPosID
+	}
+	if pos.ExternalPosID == "" {
+		return entities.POS{}, &apperror.ValidationError{Msg: "pos_id is required: no default POS is configured"}
+	}
+	return pos, nil
+}
+
+// credentialHeaders overrides the default access token when the POS belongs
+// to a collector with its own credentials. CredentialsRef names one of the
+// configured collector tokens, so tokens never go to the database; a ref
+// that is not configured is an error rather than a silent fallback to the
+// default seller.
+func credentialHeaders(tokens secrets.Set, pos entities.POS) (map[string]string, error) {
+	headers, err := collectorHeaders(tokens, pos.CredentialsRef)
+	if err != nil {
+		return nil, fmt.Errorf("%w for pos %s", err, pos.ExternalPosID)
+	}
+	return headers, nil
 }
 
-func GetExternalPosID() string {
-	return os.Getenv("MERCADO_PAGO_EXTERNAL_POS_ID")
+// collectorHeaders authenticates as the collector whose token credentialsRef
+// names; an empty ref keeps the default access token.
+func collectorHeaders(tokens secrets.Set, credentialsRef string) (map[string]string, error) {
+	headers := map[string]string{}
+	if credentialsRef == "" {
+		return headers, nil
+	}
+
+	token, found := tokens.Lookup(strings.ToLower(credentialsRef))
+	if !found || token.Value() == "" {
+		return nil, fmt.Errorf("unknown credentials_ref %q", credentialsRef)
+	}
+	headers["Authorization"] = "Bearer " + token.Value()
+	return headers, nil
 }
 
 type MercadoPagoClient struct {
-	client external2.MercadoPagoClient
+	client          external2.MercadoPagoClient
+	config          config.MercadoPago
+	collectorTokens secrets.Set
+}
+
+// Option customizes the Mercado Pago provider.
+type Option func(*options)
+
+type options struct {
+	client          []func(*resty.Client)
+	collectorTokens secrets.Set
+}
+
+func buildOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTransport wraps the client's transport, e.g. to instrument calls.
+func WithTransport(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(o *options) {
+		o.client = append(o.client, func(client *resty.Client) {
+			client.SetTransport(wrap(client.GetClient().Transport))
+		})
+	}
+}
+
+// WithCollectorTokens registers the access tokens a POS credentials_ref may
+// select.
+func WithCollectorTokens(tokens secrets.Set) Option {
+	return func(o *options) {
+		o.collectorTokens = tokens
+	}
+}
+
+// WithAccessToken authenticates each request with the current value of
+// token instead of the access token fixed at construction, so a rotated
+// token is picked up on the next call. A POS with its own credentials still
+// overrides it.
+func WithAccessToken(token *secrets.Secret) Option {
+	return func(o *options) {
+		o.client = append(o.client, func(client *resty.Client) {
+			client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+				if req.Header.Get("Authorization") == "" {
+					req.SetHeader("Authorization", "Bearer "+token.Value())
+				}
+				return nil
+			})
+		})
+	}
 }
 
-func New() external2.QRCodeProvider {
+// New returns the QR code provider for the configured Mercado Pago mode,
+// defaulting to the legacy instore endpoint.
+func New(cfg config.MercadoPago, opts ...Option) external2.QRCodeProvider {
+	o := buildOptions(opts)
+	if cfg.Mode == shared.MercadoPagoModeOrders {
+		return &MercadoPagoOrdersClient{
+			client:          getClient(cfg, o.client...),
+			config:          cfg,
+			collectorTokens: o.collectorTokens,
+		}
+	}
+
 	return &MercadoPagoClient{
-		client: getClient(),
+		client:          getClient(cfg, o.client...),
+		config:          cfg,
+		collectorTokens: o.collectorTokens,
 	}
 }
 
-func getClient() external2.MercadoPagoClient {
+func getClient(cfg config.MercadoPago, opts ...func(*resty.Client)) external2.MercadoPagoClient {
+	client := resty.New().
+		SetBaseURL(cfg.Host).
+		SetHeaders(map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": "Bearer " + cfg.AccessToken,
+		}).
+		OnBeforeRequest(propagateRequestID)
+	for _, opt := range opts {
+		opt(client)
+	}
+
 	return &MercadoPagoClientRest{
-		client: resty.New().
-			SetBaseURL(viper.GetString(shared.MercadoPagoHost)).
-			SetHeaders(map[string]string{
-				"Content-Type":  "application/json",
-				"Authorization": "Bearer " + os.Getenv("MERCADO_PAGO_ACCESS_TOKEN"),
-			}),
+		client: withRetryPolicy(client, RetryPolicy{
+			Timeout:     cfg.Client.Timeout,
+			Retries:     cfg.Client.Retries,
+			WaitTime:    cfg.Client.RetryWait,
+			MaxWaitTime: cfg.Client.RetryMaxWait,
+		}),
 	}
 }
 
-func (m *MercadoPagoClient) GenerateQRCode(_ context.Context, params entities.GenerateQRCodeParams) (string, error) {
-	requestBody := presenters.RequestBodyFromParams(params)
+func (m *MercadoPagoClient) GenerateQRCode(ctx context.Context, params entities.GenerateQRCodeParams) (entities.QRCode, error) {
+	requestBody := presenters.RequestBodyFromParams(params, m.config.NotificationURL)
+	pos, err := resolvePOS(m.config, params.POS)
+	if err != nil {
+		return entities.QRCode{}, err
+	}
 
 	pathParams := []shared.BuildPathParam{
 		{
 			Key:   "user_id",
-			Value: GetSellerUserID(),
+			Value: pos.CollectorUserID,
 		},
 		{
 			Key:   "external_pos_id",
-			Value: GetExternalPosID(),
+			Value: pos.ExternalPosID,
 		},
 	}
-	resolvedPath, err := shared.BuildPath(viper.GetString(shared.MercadoPagoQRCodePath), pathParams)
+	resolvedPath, err := shared.BuildPath(m.config.QRCode.Path, pathParams)
 	if err != nil {
-		return "", err
+		return entities.QRCode{}, err
 	}
 
-	var responseDTO dtos.ResponseGenerateQRCodeDTO
-	res, reqErr := m.client.Post(resolvedPath, requestBody, &responseDTO)
-
-	if res != nil && res.IsError() {
-		fmt.Println(" ")
-		fmt.Println("❌ Erro na chamada MercadoPago")
-		fmt.Println("Status Code:", res.StatusCode())
-		fmt.Println("Request URL:", res.Request.URL)
-		fmt.Println("Response Body:", string(res.Body()))
-		fmt.Println("*** Notification URL: ", requestBody.NotificationURL)
-		fmt.Println(" ")
-
-		return "", errors.New("error in request, endpoint called: " + res.Request.URL)
+	headers, err := credentialHeaders(m.collectorTokens, pos)
+	if err != nil {
+		return entities.QRCode{}, err
 	}
+	headers[idempotencyKeyHeader] = params.PaymentID
 
-	if reqErr != nil {
-		return "", reqErr
+	var responseDTO dtos.ResponseGenerateQRCodeDTO
+	res, reqErr := m.client.PostWithHeaders(ctx, resolvedPath, headers, requestBody, &responseDTO)
+
+	if err := providerError(res, reqErr); err != nil {
+		return entities.QRCode{}, err
 	}
 
-	return responseDTO.QRData, nil
+	return entities.QRCode{
+		ProviderOrderID: responseDTO.InStoreOrderID,
+		QRData:          responseDTO.QRData,
+	}, nil
 }
 
-func (m *MercadoPagoClient) CheckPayment(_ context.Context, requestUrl string) (dtos.ResponseVerifyOrderDTO, error) {
-	fmt.Println(requestUrl)
+// CheckPayment looks up the merchant order a notification names. Only the ID
+// is taken from the notification; the path is built on the configured host,
+// so a notification cannot send the access token elsewhere.
+func (m *MercadoPagoClient) CheckPayment(ctx context.Context, resourceID string) (dtos.ResponseVerifyOrderDTO, error) {
+	requestPath, err := shared.BuildPath(m.config.MerchantOrders.Path, []shared.BuildPathParam{
+		{Key: "id", Value: url.PathEscape(resourceID)},
+	})
+	if err != nil {
+		return dtos.ResponseVerifyOrderDTO{}, err
+	}
 
 	var responseDTO dtos.ResponseVerifyOrderDTO
-	res, reqErr := m.client.Get(requestUrl, &responseDTO)
+	res, reqErr := m.client.Get(ctx, requestPath, &responseDTO)
 
-	if res != nil && res.IsError() {
-		return dtos.ResponseVerifyOrderDTO{}, errors.New("error in request, endpoint called: " + res.Request.URL + "\n")
-	}
-	if reqErr != nil {
-		return dtos.ResponseVerifyOrderDTO{}, reqErr
+	if err := providerError(res, reqErr); err != nil {
+		return dtos.ResponseVerifyOrderDTO{}, err
 	}
 
 	return responseDTO, nil
@@ -0,0 +1,47 @@
+package presenters
+
+import (
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+)
+
+const (
+	orderTypeQR        = "qr"
+	orderQRModeDynamic = "dynamic"
+)
+
+func OrderRequestBodyFromParams(params entities.GenerateQRCodeParams, externalPosID, expiration string) dtos.RequestCreateOrderDTO {
+	items := make([]dtos.RequestCreateOrderItemDTO, len(params.Items))
+	var totalAmount float64
+
+	for i, item := range params.Items {
+		totalAmount += item.Amount
+		items[i] = dtos.RequestCreateOrderItemDTO{
+			Title:        item.Name,
+			UnitPrice:    FormatAmount(item.Price),
+			Quantity:     item.Quantity,
+			UnitMeasure:  "unit",
+			ExternalCode: item.ID,
+		}
+	}
+
+	return dtos.RequestCreateOrderDTO{
+		Type:              orderTypeQR,
+		ExternalReference: params.OrderID,
+		Description:       "Order " + params.OrderID,
+		TotalAmount:       FormatAmount(totalAmount),
+		ExpirationTime:    expiration,
+		Config: dtos.RequestCreateOrderConfigDTO{
+			QR: dtos.RequestCreateOrderQRConfigDTO{
+				ExternalPosID: externalPosID,
+				Mode:          orderQRModeDynamic,
+			},
+		},
+		Transactions: dtos.RequestCreateOrderTxDTO{
+			Payments: []dtos.RequestCreateOrderPaymentDTO{
+				{Amount: FormatAmount(totalAmount)},
+			},
+		},
+		Items: items,
+	}
+}
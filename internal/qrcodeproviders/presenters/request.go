@@ -3,10 +3,11 @@ package presenters
 import (
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/dtos"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
-	"os"
 )
 
-func RequestBodyFromParams(params entities.GenerateQRCodeParams) dtos.RequestGenerateQRCodeDTO {
+// RequestBodyFromParams builds the instore QR order; Mercado Pago posts the
+// payment notifications to notificationURL.
+func RequestBodyFromParams(params entities.GenerateQRCodeParams, notificationURL string) dtos.RequestGenerateQRCodeDTO {
 	items, totalAmount := generateItems(params.Items)
 
 	return dtos.RequestGenerateQRCodeDTO{
@@ -14,7 +15,7 @@ func RequestBodyFromParams(params entities.GenerateQRCodeParams) dtos.RequestGen
 		Description:       "Order Description " + params.OrderID,
 		ExternalReference: params.OrderID,
 		Items:             items,
-		NotificationURL:   os.Getenv("WEBHOOK_URL"), //TODO adjust this field
+		NotificationURL:   notificationURL,
 		TotalAmount:       FormatDecimal(totalAmount),
 	}
 }
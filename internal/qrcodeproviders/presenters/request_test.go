@@ -10,7 +10,8 @@ import (
 
 func TestRequestBodyFromParams(t *testing.T) {
 	type args struct {
-		params entities.GenerateQRCodeParams
+		params          entities.GenerateQRCodeParams
+		notificationURL string
 	}
 	tests := []struct {
 		name string
@@ -41,12 +42,13 @@ func TestRequestBodyFromParams(t *testing.T) {
 						},
 					},
 				},
+				notificationURL: "https://payments.golunch.com/webhook/payment/check",
 			},
 			want: dtos.RequestGenerateQRCodeDTO{
 				Title:             "Order orderId",
 				Description:       "Order Description orderId",
 				ExternalReference: "orderId",
-				NotificationURL:   "",
+				NotificationURL:   "https://payments.golunch.com/webhook/payment/check",
 				TotalAmount:       103.5,
 				Items: []dtos.RequestGenerateQRCodeItemDTO{
 					{Title: "itemName",
@@ -74,7 +76,7 @@ func TestRequestBodyFromParams(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := RequestBodyFromParams(tt.args.params)
+			got := RequestBodyFromParams(tt.args.params, tt.args.notificationURL)
 			assert.Equal(t, tt.want, got)
 		})
 	}
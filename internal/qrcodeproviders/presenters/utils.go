@@ -9,3 +9,8 @@ func FormatDecimal(value float64) float64 {
 	formattedValue, _ := strconv.ParseFloat(fmt.Sprintf("%.2f", value), 64)
 	return formattedValue
 }
+
+// FormatAmount formats a value as the two-decimal string the Orders API expects.
+func FormatAmount(value float64) string {
+	return strconv.FormatFloat(FormatDecimal(value), 'f', 2, 64)
+}
@@ -0,0 +1,140 @@
+package authz
+
+import (
+	"strings"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// ContextKey is where the authorization middleware stores the caller's
+// Grants on the gin context.
+const ContextKey = "grants"
+
+type Permission string
+
+const (
+	// PermissionPaymentsRead lets a user read payments of their own orders.
+	PermissionPaymentsRead Permission = "payments:read"
+	// PermissionPaymentsReadAll lifts the own-orders restriction of
+	// PermissionPaymentsRead.
+	PermissionPaymentsReadAll Permission = "payments:read_all"
+	// PermissionPaymentsCreate lets a user pay for their own orders;
+	// PermissionPaymentsReadAll lifts the restriction here too.
+	PermissionPaymentsCreate Permission = "payments:create"
+	// PermissionPaymentsCreateCash additionally allows CASH payments.
+	PermissionPaymentsCreateCash  Permission = "payments:create_cash"
+	PermissionPaymentsCashConfirm Permission = "payments:cash_confirm"
+	PermissionPaymentsRefund      Permission = "payments:refund"
+	PermissionWebhooksReplay      Permission = "webhooks:replay"
+
+	// wildcard grants every permission.
+	wildcard Permission = "*"
+)
+
+// DefaultRoles is used when no role mapping is configured.
+var DefaultRoles = map[string][]string{
+	"admin":    {string(wildcard)},
+	"cashier":  {string(PermissionPaymentsRead), string(PermissionPaymentsReadAll), string(PermissionPaymentsCreate), string(PermissionPaymentsCreateCash), string(PermissionPaymentsCashConfirm)},
+	"customer": {string(PermissionPaymentsRead), string(PermissionPaymentsCreate)},
+}
+
+// Grants is the set of permissions held by a principal.
+type Grants map[Permission]bool
+
+func (g Grants) Has(permission Permission) bool {
+	return g[wildcard] || g[permission]
+}
+
+// Missing returns the required permissions not in the set, in the order
+// they were required.
+func (g Grants) Missing(required ...Permission) []Permission {
+	var missing []Permission
+	for _, permission := range required {
+		if !g.Has(permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
+
+// Policy maps the roles and scopes carried in the token claims to
+// permissions.
+type Policy struct {
+	roles map[string][]Permission
+}
+
+// NewPolicy builds a policy from a role → permissions mapping, falling back
+// to DefaultRoles when it is empty.
+func NewPolicy(roles map[string][]string) *Policy {
+	if len(roles) == 0 {
+		roles = DefaultRoles
+	}
+
+	policy := &Policy{roles: make(map[string][]Permission, len(roles))}
+	for role, permissions := range roles {
+		for _, permission := range permissions {
+			policy.roles[strings.ToLower(role)] = append(policy.roles[strings.ToLower(role)], Permission(permission))
+		}
+	}
+	return policy
+}
+
+// Grants resolves the permissions of claims: those of its user type and of
+// every role in custom.roles (or custom.role), plus the scopes in
+// custom.scopes (or the space separated custom.scope).
+func (p *Policy) Grants(claims *entity.CustomClaims) Grants {
+	grants := Grants{}
+	if claims == nil {
+		return grants
+	}
+
+	roles := append([]string{claims.UserType}, stringList(claims.Custom, "roles", "role")...)
+	for _, role := range roles {
+		for _, permission := range p.roles[strings.ToLower(role)] {
+			grants[permission] = true
+		}
+	}
+	for _, scope := range stringList(claims.Custom, "scopes", "scope") {
+		grants[Permission(scope)] = true
+	}
+	return grants
+}
+
+// PrincipalGrants resolves the permissions of an authenticated caller;
+// services hold exactly the scopes of their API key.
+func (p *Policy) PrincipalGrants(principal *entity.Principal) Grants {
+	switch {
+	case principal == nil:
+		return Grants{}
+	case principal.Service != nil:
+		grants := Grants{}
+		for _, scope := range principal.Service.Scopes {
+			grants[Permission(scope)] = true
+		}
+		return grants
+	default:
+		return p.Grants(principal.User)
+	}
+}
+
+// stringList reads the first present key as either a JSON array of strings
+// or a space separated string.
+func stringList(custom map[string]interface{}, keys ...string) []string {
+	for _, key := range keys {
+		switch value := custom[key].(type) {
+		case []interface{}:
+			values := make([]string, 0, len(value))
+			for _, item := range value {
+				if s, ok := item.(string); ok && s != "" {
+					values = append(values, s)
+				}
+			}
+			return values
+		case []string:
+			return value
+		case string:
+			return strings.Fields(value)
+		}
+	}
+	return nil
+}
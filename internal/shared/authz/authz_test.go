@@ -0,0 +1,68 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+func TestPolicy_Grants(t *testing.T) {
+	policy := NewPolicy(nil)
+
+	tests := []struct {
+		name     string
+		claims   *entity.CustomClaims
+		required []Permission
+		missing  []Permission
+	}{
+		{
+			name:     "Given an admin user type, it should grant everything",
+			claims:   &entity.CustomClaims{UserType: "admin"},
+			required: []Permission{PermissionPaymentsRefund, PermissionWebhooksReplay},
+		},
+		{
+			name:     "Given a customer, it should only grant own payments read",
+			claims:   &entity.CustomClaims{UserType: "customer"},
+			required: []Permission{PermissionPaymentsRead, PermissionPaymentsReadAll},
+			missing:  []Permission{PermissionPaymentsReadAll},
+		},
+		{
+			name: "Given roles in custom claims, it should grant their permissions",
+			claims: &entity.CustomClaims{
+				UserType: "customer",
+				Custom:   map[string]interface{}{"roles": []interface{}{"cashier"}},
+			},
+			required: []Permission{PermissionPaymentsReadAll, PermissionPaymentsCashConfirm},
+		},
+		{
+			name: "Given space separated scopes, it should grant them directly",
+			claims: &entity.CustomClaims{
+				Custom: map[string]interface{}{"scope": "payments:refund webhooks:replay"},
+			},
+			required: []Permission{PermissionPaymentsRefund, PermissionWebhooksReplay, PermissionPaymentsRead},
+			missing:  []Permission{PermissionPaymentsRead},
+		},
+		{
+			name:     "Given no claims, it should grant nothing",
+			required: []Permission{PermissionPaymentsRead},
+			missing:  []Permission{PermissionPaymentsRead},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.missing, policy.Grants(tt.claims).Missing(tt.required...))
+		})
+	}
+}
+
+func TestNewPolicy_ConfiguredRoles(t *testing.T) {
+	policy := NewPolicy(map[string][]string{"Support": {"payments:read", "payments:read_all"}})
+
+	grants := policy.Grants(&entity.CustomClaims{UserType: "support"})
+
+	assert.True(t, grants.Has(PermissionPaymentsReadAll))
+	assert.False(t, policy.Grants(&entity.CustomClaims{UserType: "admin"}).Has(PermissionPaymentsRead))
+}
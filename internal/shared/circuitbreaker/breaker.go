@@ -0,0 +1,189 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting probes through.
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is how many concurrent probes are allowed while half-open.
+	HalfOpenMaxCalls int
+}
+
+// Status is a point-in-time view of a breaker, used by the readiness check
+// and the metrics.
+type Status struct {
+	Name                string     `json:"name"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker is a consecutive-failure circuit breaker. While open it rejects
+// calls immediately with a ProviderUnavailableError; after OpenTimeout it
+// goes half-open and lets a limited number of probes decide whether to close
+// again or reopen.
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu               sync.Mutex
+	state            State
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+}
+
+func New(name string, settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenMaxCalls <= 0 {
+		settings.HalfOpenMaxCalls = 1
+	}
+
+	return &Breaker{
+		name:     name,
+		settings: settings,
+		now:      time.Now,
+		state:    StateClosed,
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Execute runs fn unless the breaker is open. Only errors that signal the
+// dependency is unavailable count as failures; business errors such as
+// validation or not found pass through without tripping the breaker.
+func (b *Breaker) Execute(fn func() error) error {
+	if err := b.before(); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.after(isFailure(err))
+	return err
+}
+
+// Call is Execute for functions that return a value.
+func Call[T any](b *Breaker, fn func() (T, error)) (T, error) {
+	var result T
+	err := b.Execute(func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
+
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refreshState()
+	status := Status{
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+func (b *Breaker) before() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refreshState()
+	switch b.state {
+	case StateOpen:
+		return b.openError()
+	case StateHalfOpen:
+		if b.halfOpenInFlight >= b.settings.HalfOpenMaxCalls {
+			return b.openError()
+		}
+		b.halfOpenInFlight++
+	}
+	return nil
+}
+
+func (b *Breaker) after(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.halfOpenInFlight--
+		if failed {
+			b.open()
+			return
+		}
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateClosed && b.failures >= b.settings.FailureThreshold {
+		b.open()
+	}
+}
+
+// refreshState moves an open breaker to half-open once the timeout elapsed.
+func (b *Breaker) refreshState() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.state = StateHalfOpen
+		b.halfOpenInFlight = 0
+	}
+}
+
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.halfOpenInFlight = 0
+}
+
+func (b *Breaker) openError() error {
+	return &apperror.ProviderUnavailableError{
+		Provider: b.name,
+		Msg:      "circuit breaker is open",
+	}
+}
+
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var unavailableErr *apperror.ProviderUnavailableError
+	return errors.As(err, &unavailableErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,116 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+)
+
+var errUnavailable = &apperror.ProviderUnavailableError{Provider: "core", Msg: "status 503"}
+
+func newTestBreaker(now *time.Time) *Breaker {
+	breaker := New("core", Settings{
+		FailureThreshold: 2,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenMaxCalls: 1,
+	})
+	breaker.now = func() time.Time { return *now }
+	return breaker
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+
+	assert.Equal(t, errUnavailable, breaker.Execute(func() error { return errUnavailable }))
+	assert.Equal(t, StateClosed, breaker.Status().State)
+	assert.Equal(t, errUnavailable, breaker.Execute(func() error { return errUnavailable }))
+	assert.Equal(t, StateOpen, breaker.Status().State)
+
+	called := false
+	err := breaker.Execute(func() error {
+		called = true
+		return nil
+	})
+
+	var unavailableErr *apperror.ProviderUnavailableError
+	assert.ErrorAs(t, err, &unavailableErr)
+	assert.Equal(t, "core unavailable: circuit breaker is open", err.Error())
+	assert.False(t, called)
+}
+
+func TestBreaker_BusinessErrorsDoNotTrip(t *testing.T) {
+	now := time.Now()
+	breaker := newTestBreaker(&now)
+	notFound := &apperror.NotFoundError{Msg: "order not found"}
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, notFound, breaker.Execute(func() error { return notFound }))
+	}
+	assert.Equal(t, StateClosed, breaker.Status().State)
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name          string
+		probeErr      error
+		expectedState State
+	}{
+		{
+			name:          "Given a successful probe, it should close",
+			probeErr:      nil,
+			expectedState: StateClosed,
+		},
+		{
+			name:          "Given a failed probe, it should reopen",
+			probeErr:      errUnavailable,
+			expectedState: StateOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			breaker := newTestBreaker(&now)
+			breaker.Execute(func() error { return errUnavailable })
+			breaker.Execute(func() error { return errUnavailable })
+
+			now = now.Add(11 * time.Second)
+			assert.Equal(t, StateHalfOpen, breaker.Status().State)
+
+			probeStarted := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan error)
+			go func() {
+				done <- breaker.Execute(func() error {
+					close(probeStarted)
+					<-release
+					return tt.probeErr
+				})
+			}()
+
+			<-probeStarted
+			concurrentErr := breaker.Execute(func() error { return nil })
+			assert.Error(t, concurrentErr, "only one probe is allowed while half-open")
+
+			close(release)
+			assert.True(t, errors.Is(<-done, tt.probeErr) || tt.probeErr == nil)
+			assert.Equal(t, tt.expectedState, breaker.Status().State)
+		})
+	}
+}
+
+func TestRegistry_Statuses(t *testing.T) {
+	registry := NewRegistry(Settings{})
+	registry.Get("mercadopago")
+	registry.Get("core")
+
+	assert.Same(t, registry.Get("core"), registry.Get("core"))
+	statuses := registry.Statuses()
+	assert.Equal(t, "core", statuses[0].Name)
+	assert.Equal(t, "mercadopago", statuses[1].Name)
+}
@@ -0,0 +1,56 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry keeps every breaker of the service so their state can be reported
+// in one place.
+type Registry struct {
+	settings Settings
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewRegistry(settings Settings) *Registry {
+	return &Registry{
+		settings: settings,
+		breakers: map[string]*Breaker{},
+	}
+}
+
+// Get returns the breaker for name, creating it with the registry settings
+// on first use.
+func (r *Registry) Get(name string) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if breaker, ok := r.breakers[name]; ok {
+		return breaker
+	}
+
+	breaker := New(name, r.settings)
+	r.breakers[name] = breaker
+	return breaker
+}
+
+// Statuses returns the status of every breaker sorted by name.
+func (r *Registry) Statuses() []Status {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		breakers = append(breakers, breaker)
+	}
+	r.mu.Unlock()
+
+	statuses := make([]Status, 0, len(breakers))
+	for _, breaker := range breakers {
+		statuses = append(statuses, breaker.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,359 @@
+// Package config loads the service configuration into a typed struct.
+//
+// Values come from the YAML file (conf/environment/default.yml by default),
+// then environment variables, then command-line flags, each overriding the
+// previous one. Besides the documented variables (MONGODB_URI,
+// PAYMENT_SERVICE_PORT, ...), any key can be overridden with PAYMENT_ plus
+// its upper-cased path, e.g. PAYMENT_APP_RATE_LIMIT_BACKEND.
+//
+// Rotatable secrets also have a *_file key naming a mounted file that takes
+// precedence over the inline value; see package secrets.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/mtls"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/ratelimit"
+)
+
+const (
+	// DefaultFile is read when neither -config nor CONFIG_FILE is set.
+	DefaultFile = "conf/environment/default.yml"
+
+	envPrefix = "PAYMENT"
+)
+
+// envBindings maps the environment variables the deployments already use to
+// their configuration keys.
+var envBindings = map[string]string{
+	"app.server.port":                               "PAYMENT_SERVICE_PORT",
+	"app.log.level":                                 "LOG_LEVEL",
+	"app.mongodb.uri":                               "MONGODB_URI",
+	"app.mongodb.database":                          "MONGODB_DATABASE",
+	"app.core.url":                                  "CORE_SERVICE_URL",
+	"app.core.api_key":                              "PAYMENT_SERVICE_API_KEY",
+	"app.core.api_key_file":                         "PAYMENT_SERVICE_API_KEY_FILE",
+	"app.auth.lambda.token_url":                     "AUTH_TOKEN_URL",
+	"app.auth.lambda.service_url":                   "AUTH_SERVICE_URL",
+	"app.auth.jwt.hmac_secret":                      "JWT_SECRET",
+	"app.service_auth.signing.secret":               "SERVICE_SIGNING_SECRET",
+	"app.service_auth.signing.secret_file":          "SERVICE_SIGNING_SECRET_FILE",
+	"app.providers.mercadopago.access_token":        "MERCADO_PAGO_ACCESS_TOKEN",
+	"app.providers.mercadopago.access_token_file":   "MERCADO_PAGO_ACCESS_TOKEN_FILE",
+	"app.providers.mercadopago.webhook_secret":      "MERCADO_PAGO_WEBHOOK_SECRET",
+	"app.providers.mercadopago.webhook_secret_file": "MERCADO_PAGO_WEBHOOK_SECRET_FILE",
+	"app.providers.mercadopago.seller_user_id":      "MERCADO_PAGO_SELLER_APP_USER_ID",
+	"app.providers.mercadopago.external_pos_id":     "MERCADO_PAGO_EXTERNAL_POS_ID",
+	"app.providers.mercadopago.notification_url":    "WEBHOOK_URL",
+}
+
+// Config is the whole service configuration, the app section of the YAML
+// file.
+type Config struct {
+	Server      Server      `mapstructure:"server"`
+	Log         Log         `mapstructure:"log"`
+	MongoDB     MongoDB     `mapstructure:"mongodb"`
+	Core        Core        `mapstructure:"core"`
+	Auth        Auth        `mapstructure:"auth"`
+	ServiceAuth ServiceAuth `mapstructure:"service_auth"`
+	MTLS        MTLS        `mapstructure:"mtls"`
+	RateLimit   RateLimit   `mapstructure:"rate_limit"`
+	Health      Health      `mapstructure:"health"`
+	Tracing     Tracing     `mapstructure:"tracing"`
+	Authz       Authz       `mapstructure:"authz"`
+	Resilience  Resilience  `mapstructure:"resilience"`
+	Providers   Providers   `mapstructure:"providers"`
+	Secrets     Secrets     `mapstructure:"secrets"`
+}
+
+type Server struct {
+	Port            int           `mapstructure:"port"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// ShutdownDelay is how long readiness fails before connections stop
+	// being accepted, so the load balancer stops routing first.
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+	// TrustedProxies are the IPs or CIDRs whose X-Forwarded-For is honoured
+	// when resolving the client IP; empty trusts none.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// Addr is the address the HTTP server listens on.
+func (s Server) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+type Log struct {
+	Level string `mapstructure:"level"`
+}
+
+type MongoDB struct {
+	URI      string `mapstructure:"uri"`
+	Database string `mapstructure:"database"`
+}
+
+type Core struct {
+	URL        string `mapstructure:"url"`
+	APIKey     string `mapstructure:"api_key"`
+	APIKeyFile string `mapstructure:"api_key_file"`
+}
+
+type Auth struct {
+	Mode   string     `mapstructure:"mode"`
+	Lambda AuthLambda `mapstructure:"lambda"`
+	JWT    AuthJWT    `mapstructure:"jwt"`
+	Cache  AuthCache  `mapstructure:"cache"`
+}
+
+type AuthLambda struct {
+	TokenURL   string        `mapstructure:"token_url"`
+	ServiceURL string        `mapstructure:"service_url"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+type AuthJWT struct {
+	JWKSURL       string        `mapstructure:"jwks_url"`
+	JWKSRefresh   time.Duration `mapstructure:"jwks_refresh"`
+	PublicKeyFile string        `mapstructure:"public_key_file"`
+	HMACSecret    string        `mapstructure:"hmac_secret"`
+	Leeway        time.Duration `mapstructure:"leeway"`
+}
+
+type AuthCache struct {
+	MaxEntries  int           `mapstructure:"max_entries"`
+	TTL         time.Duration `mapstructure:"ttl"`
+	NegativeTTL time.Duration `mapstructure:"negative_ttl"`
+}
+
+type ServiceAuth struct {
+	Credentials ServiceCredentials `mapstructure:"credentials"`
+	Signing     ServiceSigning     `mapstructure:"signing"`
+}
+
+type ServiceCredentials struct {
+	Source         string        `mapstructure:"source"`
+	File           string        `mapstructure:"file"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+type ServiceSigning struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Required bool          `mapstructure:"required"`
+	MaxSkew  time.Duration `mapstructure:"max_skew"`
+	// SecretsFile holds the callers' secrets; it is re-read every
+	// app.secrets.reload_interval.
+	SecretsFile string `mapstructure:"secrets_file"`
+	// NonceBackend is memory (per replica) or mongo (shared by replicas).
+	NonceBackend string `mapstructure:"nonce_backend"`
+	// Secret signs outbound calls to Core; empty leaves them unsigned.
+	Secret     string `mapstructure:"secret"`
+	SecretFile string `mapstructure:"secret_file"`
+}
+
+type MTLS struct {
+	Server MTLSServer `mapstructure:"server"`
+	Client MTLSClient `mapstructure:"client"`
+}
+
+type MTLSServer struct {
+	Enabled           bool            `mapstructure:"enabled"`
+	CertFile          string          `mapstructure:"cert_file"`
+	KeyFile           string          `mapstructure:"key_file"`
+	ClientCAFile      string          `mapstructure:"client_ca_file"`
+	RequireClientCert bool            `mapstructure:"require_client_cert"`
+	Identities        []mtls.Identity `mapstructure:"identities"`
+}
+
+type MTLSClient struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	CAFile   string `mapstructure:"ca_file"`
+}
+
+type RateLimit struct {
+	Backend string                     `mapstructure:"backend"`
+	Groups  map[string]ratelimit.Limit `mapstructure:"groups"`
+}
+
+type Health struct {
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+	CorePath     string        `mapstructure:"core_path"`
+}
+
+type Tracing struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	ServiceName string  `mapstructure:"service_name"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type Authz struct {
+	Roles map[string][]string `mapstructure:"roles"`
+}
+
+type Resilience struct {
+	CircuitBreaker CircuitBreaker `mapstructure:"circuit_breaker"`
+}
+
+type CircuitBreaker struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+	HalfOpenMaxCalls int           `mapstructure:"half_open_max_calls"`
+}
+
+type Providers struct {
+	MercadoPago MercadoPago `mapstructure:"mercadopago"`
+}
+
+type MercadoPago struct {
+	Host            string `mapstructure:"host"`
+	AccessToken     string `mapstructure:"access_token"`
+	AccessTokenFile string `mapstructure:"access_token_file"`
+	// SellerUserID and ExternalPosID address the default POS, used when a
+	// payment does not name a registered one. Both are optional; see
+	// DefaultPOS.
+	SellerUserID    string `mapstructure:"seller_user_id"`
+	ExternalPosID   string `mapstructure:"external_pos_id"`
+	NotificationURL string `mapstructure:"notification_url"`
+	// CollectorTokens are the access tokens of collectors with their own
+	// credentials, keyed by the credentials_ref a POS stores; no other ref
+	// resolves. Keys are case-insensitive.
+	CollectorTokens map[string]SecretSource `mapstructure:"collector_tokens"`
+	// WebhookSecret verifies the x-signature of notifications, whose
+	// timestamp must be within WebhookTolerance of now. It is required
+	// unless WebhookAllowUnsigned is set.
+	WebhookSecret        string            `mapstructure:"webhook_secret"`
+	WebhookSecretFile    string            `mapstructure:"webhook_secret_file"`
+	WebhookTolerance     time.Duration     `mapstructure:"webhook_tolerance"`
+	WebhookAllowUnsigned bool              `mapstructure:"webhook_allow_unsigned"`
+	Mode                 string            `mapstructure:"mode"`
+	Client               MercadoPagoClient `mapstructure:"client"`
+	QRCode               Path              `mapstructure:"qrcode"`
+	MerchantOrders       Path              `mapstructure:"merchant_orders"`
+	Orders               MercadoPagoOrders `mapstructure:"orders"`
+	Stores               MercadoPagoStores `mapstructure:"stores"`
+	POS                  Path              `mapstructure:"pos"`
+}
+
+// DefaultPOS returns the settings the default POS needs in the configured
+// mode, keyed by environment variable, or nil when no default POS is
+// configured and every QR payment must name a registered POS. The instore
+// endpoint addresses a POS through its collector; the Orders API only needs
+// the external POS ID.
+func (mp MercadoPago) DefaultPOS() map[string]string {
+	if mp.Mode == shared.MercadoPagoModeOrders {
+		if mp.ExternalPosID == "" {
+			return nil
+		}
+		return map[string]string{"MERCADO_PAGO_EXTERNAL_POS_ID": mp.ExternalPosID}
+	}
+
+	if mp.SellerUserID == "" && mp.ExternalPosID == "" {
+		return nil
+	}
+	return map[string]string{
+		"MERCADO_PAGO_SELLER_APP_USER_ID": mp.SellerUserID,
+		"MERCADO_PAGO_EXTERNAL_POS_ID":    mp.ExternalPosID,
+	}
+}
+
+type MercadoPagoClient struct {
+	Timeout      time.Duration `mapstructure:"timeout"`
+	Retries      int           `mapstructure:"retries"`
+	RetryWait    time.Duration `mapstructure:"retry_wait"`
+	RetryMaxWait time.Duration `mapstructure:"retry_max_wait"`
+}
+
+type Path struct {
+	Path string `mapstructure:"path"`
+}
+
+type MercadoPagoOrders struct {
+	Path       string `mapstructure:"path"`
+	Expiration string `mapstructure:"expiration"`
+}
+
+type MercadoPagoStores struct {
+	Path       string `mapstructure:"path"`
+	SearchPath string `mapstructure:"search_path"`
+}
+
+// SecretSource is a secret given inline or, taking precedence, as a mounted
+// file.
+type SecretSource struct {
+	Value string `mapstructure:"value"`
+	File  string `mapstructure:"file"`
+}
+
+// Secrets controls how often file-backed secrets are re-read.
+type Secrets struct {
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// Load reads the configuration for a command invoked with args (without
+// the program name). It does not validate it; see Validate.
+func Load(name string, args []string) (Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	file := fs.String("config", "", "configuration file (default "+DefaultFile+", or CONFIG_FILE)")
+	port := fs.Int("port", 0, "HTTP port, overrides app.server.port")
+	logLevel := fs.String("log-level", "", "log level, overrides app.log.level")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return Config{}, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(configFile(*file))
+	if err := v.ReadInConfig(); err != nil {
+		return Config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for key, env := range envBindings {
+		if err := v.BindEnv(key, env); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if *port != 0 {
+		v.Set("app.server.port", *port)
+	}
+	if *logLevel != "" {
+		v.Set("app.log.level", *logLevel)
+	}
+
+	var root struct {
+		App Config `mapstructure:"app"`
+	}
+	if err := v.Unmarshal(&root); err != nil {
+		return Config{}, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return root.App, nil
+}
+
+func configFile(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv("CONFIG_FILE"); env != "" {
+		return env
+	}
+	return DefaultFile
+}
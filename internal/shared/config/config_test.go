@@ -0,0 +1,293 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAML = `
+app:
+  server:
+    port: 8082
+    shutdown_timeout: 25s
+    shutdown_delay: 5s
+    trusted_proxies: []
+  log:
+    level: info
+  mongodb:
+    uri: mongodb://localhost:27017
+    database: golunch_payments
+  core:
+    url: http://core:8081
+  auth:
+    mode: lambda
+    lambda:
+      token_url: https://auth.example.com/token
+      service_url: https://auth.example.com/service
+      timeout: 10s
+  rate_limit:
+    backend: memory
+    groups:
+      webhook:
+        requests_per_minute: 600
+        burst: 100
+  health:
+    check_timeout: 2s
+  tracing:
+    sample_ratio: 1
+  resilience:
+    circuit_breaker:
+      failure_threshold: 5
+      open_timeout: 30s
+      half_open_max_calls: 1
+  providers:
+    mercadopago:
+      host: https://api.mercadopago.com
+      access_token: token
+      webhook_secret: webhook-secret
+      webhook_tolerance: 5m
+      webhook_allow_unsigned: false
+      mode: instore
+      client:
+        timeout: 10s
+      qrcode:
+        path: /instore/qr/seller/collectors/{user_id}/pos/{external_pos_id}/qrs
+      merchant_orders:
+        path: /merchant_orders/{id}
+  secrets:
+    reload_interval: 30s
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, testYAML)
+
+	t.Run("Given only the YAML file, it should decode every section", func(t *testing.T) {
+		cfg, err := Load("test", []string{"-config", path})
+
+		require.NoError(t, err)
+		assert.Equal(t, 8082, cfg.Server.Port)
+		assert.Equal(t, 25*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, 5*time.Second, cfg.Server.ShutdownDelay)
+		assert.Equal(t, "golunch_payments", cfg.MongoDB.Database)
+		assert.Equal(t, 600.0, cfg.RateLimit.Groups["webhook"].RequestsPerMinute)
+		assert.Equal(t, "token", cfg.Providers.MercadoPago.AccessToken)
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("Given documented and prefixed env vars, it should override the file", func(t *testing.T) {
+		t.Setenv("PAYMENT_SERVICE_PORT", "9090")
+		t.Setenv("MERCADO_PAGO_ACCESS_TOKEN", "from-env")
+		t.Setenv("PAYMENT_APP_RATE_LIMIT_BACKEND", "mongo")
+
+		cfg, err := Load("test", []string{"-config", path})
+
+		require.NoError(t, err)
+		assert.Equal(t, 9090, cfg.Server.Port)
+		assert.Equal(t, ":9090", cfg.Server.Addr())
+		assert.Equal(t, "from-env", cfg.Providers.MercadoPago.AccessToken)
+		assert.Equal(t, "mongo", cfg.RateLimit.Backend)
+	})
+
+	t.Run("Given flags, it should override env vars", func(t *testing.T) {
+		t.Setenv("PAYMENT_SERVICE_PORT", "9090")
+		t.Setenv("LOG_LEVEL", "warn")
+
+		cfg, err := Load("test", []string{"-config", path, "-port", "7070", "-log-level", "debug"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 7070, cfg.Server.Port)
+		assert.Equal(t, "debug", cfg.Log.Level)
+	})
+
+	t.Run("Given CONFIG_FILE, it should read that file", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", path)
+
+		cfg, err := Load("test", nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://core:8081", cfg.Core.URL)
+	})
+
+	t.Run("Given a missing file, it should return an error", func(t *testing.T) {
+		_, err := Load("test", []string{"-config", filepath.Join(t.TempDir(), "missing.yml")})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Given an unknown flag, it should return an error", func(t *testing.T) {
+		_, err := Load("test", []string{"-config", path, "-nope"})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Given several invalid settings, it should report all of them", func(t *testing.T) {
+		t.Setenv("PAYMENT_SERVICE_PORT", "70000")
+		t.Setenv("CORE_SERVICE_URL", "core-service")
+		t.Setenv("PAYMENT_APP_AUTH_MODE", "oauth")
+		t.Setenv("PAYMENT_APP_PROVIDERS_MERCADOPAGO_MODE", "orders")
+
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+		cfg.Providers.MercadoPago.AccessToken = ""
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.ElementsMatch(t, []string{
+			"app.server.port: must be between 1 and 65535, got 70000",
+			`app.core.url (CORE_SERVICE_URL): must be an absolute URL, got "core-service"`,
+			`app.auth.mode: must be one of "local", "lambda", got "oauth"`,
+			"app.providers.mercadopago.access_token (MERCADO_PAGO_ACCESS_TOKEN): is required",
+			"app.providers.mercadopago.orders.path: is required",
+		}, validationErr.Problems)
+	})
+
+	t.Run("Given the access token in a file, it should not require it inline", func(t *testing.T) {
+		t.Setenv("MERCADO_PAGO_ACCESS_TOKEN_FILE", "/var/run/secrets/payment/mp-token")
+
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+		cfg.Providers.MercadoPago.AccessToken = ""
+
+		assert.Equal(t, "/var/run/secrets/payment/mp-token", cfg.Providers.MercadoPago.AccessTokenFile)
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("Given a collector token without value or file, it should report it", func(t *testing.T) {
+		cfg, err := Load("test", []string{"-config", writeConfig(t, strings.Replace(testYAML, "      access_token: token\n", `      access_token: token
+      collector_tokens:
+        STORE2:
+          file: /etc/payment-service/secrets/store2
+        store3:
+          value: ""
+`, 1))})
+		require.NoError(t, err)
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.Equal(t, []string{"app.providers.mercadopago.collector_tokens.store3: needs value or file"}, validationErr.Problems)
+		assert.Contains(t, cfg.Providers.MercadoPago.CollectorTokens, "store2", "refs should be case-insensitive")
+	})
+
+	t.Run("Given half of the instore default POS, it should require the other half", func(t *testing.T) {
+		t.Setenv("MERCADO_PAGO_EXTERNAL_POS_ID", "POS001")
+
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.Equal(t, []string{
+			"app.providers.mercadopago.seller_user_id (MERCADO_PAGO_SELLER_APP_USER_ID), for the default POS: is required",
+		}, validationErr.Problems)
+	})
+
+	t.Run("Given trusted proxies from the env, it should split them and reject non-CIDRs", func(t *testing.T) {
+		t.Setenv("PAYMENT_APP_SERVER_TRUSTED_PROXIES", "10.0.0.0/8,192.168.1.10,ingress")
+
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10", "ingress"}, cfg.Server.TrustedProxies)
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.Equal(t, []string{`app.server.trusted_proxies: must be IPs or CIDRs, got "ingress"`}, validationErr.Problems)
+	})
+
+	t.Run("Given no webhook secret, it should require one unless unsigned webhooks are allowed", func(t *testing.T) {
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+		cfg.Providers.MercadoPago.WebhookSecret = ""
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.Equal(t, []string{"app.providers.mercadopago.webhook_secret (MERCADO_PAGO_WEBHOOK_SECRET): is required unless webhook_allow_unsigned is set"}, validationErr.Problems)
+
+		t.Setenv("PAYMENT_APP_PROVIDERS_MERCADOPAGO_WEBHOOK_ALLOW_UNSIGNED", "true")
+		cfg, err = Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+		cfg.Providers.MercadoPago.WebhookSecret = ""
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("Given local auth without a key source, it should require one", func(t *testing.T) {
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+		cfg.Auth.Mode = "local"
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.Equal(t, []string{"app.auth.jwt: local mode needs jwks_url, public_key_file or JWT_SECRET"}, validationErr.Problems)
+	})
+
+	t.Run("Given mTLS and signing enabled without files, it should report each missing file", func(t *testing.T) {
+		cfg, err := Load("test", []string{"-config", writeConfig(t, testYAML)})
+		require.NoError(t, err)
+		cfg.MTLS.Server.Enabled = true
+		cfg.MTLS.Server.RequireClientCert = false
+		cfg.ServiceAuth.Signing.Enabled = true
+		cfg.ServiceAuth.Signing.MaxSkew = time.Minute
+		cfg.ServiceAuth.Signing.NonceBackend = "mongo"
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(cfg.Validate(), &validationErr))
+		assert.Equal(t, []string{
+			"app.service_auth.signing.secrets_file: is required",
+			"app.mtls.server.cert_file: is required",
+			"app.mtls.server.key_file: is required",
+			"app.mtls.server.client_ca_file: is required",
+		}, validationErr.Problems)
+	})
+}
+
+func TestMercadoPago_DefaultPOS(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   MercadoPago
+		expected map[string]string
+	}{
+		{
+			name:   "Given no default POS, it should require nothing",
+			config: MercadoPago{Mode: "instore"},
+		},
+		{
+			name:   "Given an instore default POS, it should require the seller and the POS",
+			config: MercadoPago{Mode: "instore", ExternalPosID: "POS001"},
+			expected: map[string]string{
+				"MERCADO_PAGO_SELLER_APP_USER_ID": "",
+				"MERCADO_PAGO_EXTERNAL_POS_ID":    "POS001",
+			},
+		},
+		{
+			name:     "Given orders mode, it should not require the seller",
+			config:   MercadoPago{Mode: "orders", SellerUserID: "123", ExternalPosID: "POS001"},
+			expected: map[string]string{"MERCADO_PAGO_EXTERNAL_POS_ID": "POS001"},
+		},
+		{
+			name:   "Given orders mode with only a seller, it should have no default POS",
+			config: MercadoPago{Mode: "orders", SellerUserID: "123"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.DefaultPOS())
+		})
+	}
+}
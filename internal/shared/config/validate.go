@@ -0,0 +1,260 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared"
+)
+
+// ValidationError lists every problem found in the configuration, so a
+// broken deployment is fixed in one go instead of one restart per setting.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+type problems []string
+
+func (p *problems) add(key, format string, args ...any) {
+	*p = append(*p, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (p *problems) required(key, value string) {
+	if value == "" {
+		p.add(key, "is required")
+	}
+}
+
+func (p *problems) url(key, value string) {
+	if value == "" {
+		p.add(key, "is required")
+		return
+	}
+	if parsed, err := url.Parse(value); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		p.add(key, "must be an absolute URL, got %q", value)
+	}
+}
+
+func (p *problems) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	p.add(key, "must be one of %s, got %q", strings.Join(quoted(allowed), ", "), value)
+}
+
+func quoted(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = fmt.Sprintf("%q", v)
+	}
+	return out
+}
+
+// Validate checks the whole configuration and returns a *ValidationError
+// listing every problem, or nil.
+func (c Config) Validate() error {
+	var p problems
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		p.add("app.server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		p.add("app.server.shutdown_timeout", "must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		p.add("app.server.shutdown_delay", "must not be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			p.add("app.server.trusted_proxies", "must be IPs or CIDRs, got %q", proxy)
+		}
+	}
+	p.oneOf("app.log.level", strings.ToLower(c.Log.Level), "", "debug", "info", "warn", "warning", "error")
+
+	p.required("app.mongodb.uri (MONGODB_URI)", c.MongoDB.URI)
+	p.required("app.mongodb.database (MONGODB_DATABASE)", c.MongoDB.Database)
+	p.url("app.core.url (CORE_SERVICE_URL)", c.Core.URL)
+
+	c.validateAuth(&p)
+	c.validateServiceAuth(&p)
+	c.validateMTLS(&p)
+
+	p.oneOf("app.rate_limit.backend", c.RateLimit.Backend, shared.RateLimitBackendMemory, shared.RateLimitBackendMongo)
+	groups := make([]string, 0, len(c.RateLimit.Groups))
+	for group := range c.RateLimit.Groups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		if limit := c.RateLimit.Groups[group]; limit.RequestsPerMinute < 0 || limit.Burst < 0 {
+			p.add("app.rate_limit.groups."+group, "requests_per_minute and burst must not be negative")
+		}
+	}
+
+	if c.Health.CheckTimeout <= 0 {
+		p.add("app.health.check_timeout", "must be positive")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		p.add("app.tracing.sample_ratio", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+	if c.Tracing.Enabled {
+		p.required("app.tracing.service_name", c.Tracing.ServiceName)
+	}
+
+	breaker := c.Resilience.CircuitBreaker
+	if breaker.FailureThreshold < 1 {
+		p.add("app.resilience.circuit_breaker.failure_threshold", "must be at least 1")
+	}
+	if breaker.OpenTimeout <= 0 {
+		p.add("app.resilience.circuit_breaker.open_timeout", "must be positive")
+	}
+	if breaker.HalfOpenMaxCalls < 1 {
+		p.add("app.resilience.circuit_breaker.half_open_max_calls", "must be at least 1")
+	}
+
+	c.validateMercadoPago(&p)
+
+	if c.Secrets.ReloadInterval <= 0 {
+		p.add("app.secrets.reload_interval", "must be positive")
+	}
+
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+func (c Config) validateAuth(p *problems) {
+	p.oneOf("app.auth.mode", c.Auth.Mode, shared.AuthModeLocal, shared.AuthModeLambda)
+	if c.Auth.Lambda.Timeout <= 0 {
+		p.add("app.auth.lambda.timeout", "must be positive")
+	}
+	if c.Auth.Cache.MaxEntries < 0 {
+		p.add("app.auth.cache.max_entries", "must not be negative")
+	}
+
+	switch c.Auth.Mode {
+	case shared.AuthModeLambda:
+		p.url("app.auth.lambda.token_url (AUTH_TOKEN_URL)", c.Auth.Lambda.TokenURL)
+		p.url("app.auth.lambda.service_url (AUTH_SERVICE_URL)", c.Auth.Lambda.ServiceURL)
+	case shared.AuthModeLocal:
+		jwt := c.Auth.JWT
+		if jwt.JWKSURL == "" && jwt.PublicKeyFile == "" && jwt.HMACSecret == "" {
+			p.add("app.auth.jwt", "local mode needs jwks_url, public_key_file or JWT_SECRET")
+		}
+	}
+}
+
+func (c Config) validateServiceAuth(p *problems) {
+	credentials := c.ServiceAuth.Credentials
+	p.oneOf("app.service_auth.credentials.source", credentials.Source, "", shared.ServiceCredentialsSourceFile, shared.ServiceCredentialsSourceMongo)
+	if credentials.Source == shared.ServiceCredentialsSourceFile {
+		p.required("app.service_auth.credentials.file", credentials.File)
+	}
+	if credentials.Source != "" && credentials.ReloadInterval <= 0 {
+		p.add("app.service_auth.credentials.reload_interval", "must be positive")
+	}
+
+	signing := c.ServiceAuth.Signing
+	if signing.Enabled {
+		p.required("app.service_auth.signing.secrets_file", signing.SecretsFile)
+		if signing.MaxSkew <= 0 {
+			p.add("app.service_auth.signing.max_skew", "must be positive")
+		}
+		p.oneOf("app.service_auth.signing.nonce_backend", signing.NonceBackend, shared.SigningNonceBackendMemory, shared.SigningNonceBackendMongo)
+	}
+}
+
+func (c Config) validateMTLS(p *problems) {
+	server := c.MTLS.Server
+	if server.Enabled {
+		p.required("app.mtls.server.cert_file", server.CertFile)
+		p.required("app.mtls.server.key_file", server.KeyFile)
+		// Optional client certificates are still verified, so the CA is
+		// needed either way.
+		p.required("app.mtls.server.client_ca_file", server.ClientCAFile)
+	}
+
+	client := c.MTLS.Client
+	if client.Enabled {
+		p.required("app.mtls.client.cert_file", client.CertFile)
+		p.required("app.mtls.client.key_file", client.KeyFile)
+	}
+}
+
+func (c Config) validateMercadoPago(p *problems) {
+	mp := c.Providers.MercadoPago
+	p.url("app.providers.mercadopago.host", mp.Host)
+	if mp.AccessTokenFile == "" {
+		p.required("app.providers.mercadopago.access_token (MERCADO_PAGO_ACCESS_TOKEN)", mp.AccessToken)
+	}
+	p.oneOf("app.providers.mercadopago.mode", mp.Mode, shared.MercadoPagoModeInStore, shared.MercadoPagoModeOrders)
+	defaultPOS := mp.DefaultPOS()
+	for _, env := range sortedKeys(defaultPOS) {
+		p.required(envKey(env)+" ("+env+"), for the default POS", defaultPOS[env])
+	}
+	if mp.NotificationURL != "" {
+		p.url("app.providers.mercadopago.notification_url (WEBHOOK_URL)", mp.NotificationURL)
+	}
+
+	if !mp.WebhookAllowUnsigned && mp.WebhookSecretFile == "" && mp.WebhookSecret == "" {
+		p.add("app.providers.mercadopago.webhook_secret (MERCADO_PAGO_WEBHOOK_SECRET)", "is required unless webhook_allow_unsigned is set")
+	}
+	if mp.WebhookTolerance <= 0 {
+		p.add("app.providers.mercadopago.webhook_tolerance", "must be positive")
+	}
+
+	refs := make([]string, 0, len(mp.CollectorTokens))
+	for ref := range mp.CollectorTokens {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		if source := mp.CollectorTokens[ref]; source.Value == "" && source.File == "" {
+			p.add("app.providers.mercadopago.collector_tokens."+ref, "needs value or file")
+		}
+	}
+
+	if mp.Client.Timeout <= 0 {
+		p.add("app.providers.mercadopago.client.timeout", "must be positive")
+	}
+	if mp.Client.Retries < 0 {
+		p.add("app.providers.mercadopago.client.retries", "must not be negative")
+	}
+
+	switch mp.Mode {
+	case shared.MercadoPagoModeInStore:
+		p.required("app.providers.mercadopago.qrcode.path", mp.QRCode.Path)
+		p.required("app.providers.mercadopago.merchant_orders.path", mp.MerchantOrders.Path)
+	case shared.MercadoPagoModeOrders:
+		p.required("app.providers.mercadopago.orders.path", mp.Orders.Path)
+	}
+}
+
+// envKey returns the configuration key an environment variable is bound to.
+func envKey(env string) string {
+	for key, bound := range envBindings {
+		if bound == env {
+			return key
+		}
+	}
+	return env
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
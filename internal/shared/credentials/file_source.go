@@ -0,0 +1,51 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource reads credentials from a YAML or JSON file (by extension):
+//
+//	services:
+//	  - service: core-service
+//	    keys:
+//	      - id: core-2025-10
+//	        hash: sha256:...
+//	        expires_at: 2025-11-01T00:00:00Z
+//	        scopes: [payments:read]
+type FileSource struct {
+	path string
+}
+
+type credentialsFile struct {
+	Services []ServiceCredential `json:"services" yaml:"services"`
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (f *FileSource) Load(_ context.Context) ([]ServiceCredential, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service credentials: %w", err)
+	}
+
+	var file credentialsFile
+	if filepath.Ext(f.path) == ".json" {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service credentials: %w", err)
+	}
+
+	return file.Services, nil
+}
@@ -0,0 +1,36 @@
+package credentials
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const credentialsCollection = "service_credentials"
+
+// MongoSource reads one document per service from the service_credentials
+// collection.
+type MongoSource struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSource(db *mongo.Database) *MongoSource {
+	return &MongoSource{
+		collection: db.Collection(credentialsCollection),
+	}
+}
+
+func (m *MongoSource) Load(ctx context.Context) ([]ServiceCredential, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var credentials []ServiceCredential
+	if err := cursor.All(ctx, &credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
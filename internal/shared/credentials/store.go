@@ -0,0 +1,103 @@
+package credentials
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// Source loads the full set of service credentials.
+type Source interface {
+	Load(ctx context.Context) ([]ServiceCredential, error)
+}
+
+// Store verifies service API keys against credentials loaded from a Source.
+// Reload swaps the whole set atomically; a failed reload keeps the last good
+// set so a bad edit cannot lock every caller out.
+type Store struct {
+	source Source
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	services map[string][]ServiceKey
+}
+
+func NewStore(source Source) *Store {
+	return &Store{
+		source:   source,
+		now:      time.Now,
+		services: map[string][]ServiceKey{},
+	}
+}
+
+func (s *Store) Reload(ctx context.Context) error {
+	loaded, err := s.source.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	services := make(map[string][]ServiceKey, len(loaded))
+	for _, credential := range loaded {
+		for _, key := range credential.Keys {
+			if _, err := hex.DecodeString(normalizeHash(key.Hash)); err != nil || key.Hash == "" {
+				return fmt.Errorf("service %s key %s: invalid hash", credential.Service, key.ID)
+			}
+		}
+		services[credential.Service] = append(services[credential.Service], credential.Keys...)
+	}
+
+	s.mu.Lock()
+	s.services = services
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the credentials every interval until ctx is done.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				slog.Error("failed to reload service credentials", "error", err)
+			}
+		}
+	}
+}
+
+// Verify checks apiKey against every unexpired key of the service.
+func (s *Store) Verify(serviceName, apiKey string) (*entity.ServiceClaims, error) {
+	s.mu.RLock()
+	keys, found := s.services[serviceName]
+	s.mu.RUnlock()
+
+	if !found {
+		return nil, ErrUnknownService
+	}
+
+	hash := normalizeHash(HashKey(apiKey))
+	now := s.now()
+	for _, key := range keys {
+		if key.expired(now) {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(normalizeHash(key.Hash))) == 1 {
+			return &entity.ServiceClaims{
+				ServiceName: serviceName,
+				KeyID:       key.ID,
+				Scopes:      key.Scopes,
+			}, nil
+		}
+	}
+	return nil, ErrInvalidKey
+}
@@ -0,0 +1,110 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	credentials []ServiceCredential
+	err         error
+}
+
+func (s *staticSource) Load(ctx context.Context) ([]ServiceCredential, error) {
+	return s.credentials, s.err
+}
+
+func TestStore_Verify(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	expiring := now.Add(time.Hour)
+
+	source := &staticSource{credentials: []ServiceCredential{
+		{
+			Service: "core-service",
+			Keys: []ServiceKey{
+				{ID: "core-old", Hash: HashKey("old-key"), ExpiresAt: &expiring},
+				{ID: "core-new", Hash: HashKey("new-key"), Scopes: []string{"payments:read"}},
+				{ID: "core-retired", Hash: HashKey("retired-key"), ExpiresAt: &expired},
+			},
+		},
+	}}
+	store := NewStore(source)
+	require.NoError(t, store.Reload(context.Background()))
+
+	tests := []struct {
+		name          string
+		serviceName   string
+		apiKey        string
+		expectedKeyID string
+		expectedErr   error
+	}{
+		{name: "Given the new key, it should verify", serviceName: "core-service", apiKey: "new-key", expectedKeyID: "core-new"},
+		{name: "Given the old key during the overlap, it should verify", serviceName: "core-service", apiKey: "old-key", expectedKeyID: "core-old"},
+		{name: "Given an expired key, it should fail", serviceName: "core-service", apiKey: "retired-key", expectedErr: ErrInvalidKey},
+		{name: "Given a wrong key, it should fail", serviceName: "core-service", apiKey: "wrong-key", expectedErr: ErrInvalidKey},
+		{name: "Given an unknown service, it should fail", serviceName: "unknown-service", apiKey: "new-key", expectedErr: ErrUnknownService},
+		{name: "Given an empty key, it should fail", serviceName: "core-service", apiKey: "", expectedErr: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := store.Verify(tt.serviceName, tt.apiKey)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, claims)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.serviceName, claims.ServiceName)
+			assert.Equal(t, tt.expectedKeyID, claims.KeyID)
+		})
+	}
+}
+
+func TestStore_ReloadKeepsLastGoodSet(t *testing.T) {
+	source := &staticSource{credentials: []ServiceCredential{
+		{Service: "core-service", Keys: []ServiceKey{{ID: "core-1", Hash: HashKey("key-1")}}},
+	}}
+	store := NewStore(source)
+	require.NoError(t, store.Reload(context.Background()))
+
+	source.err = errors.New("file is gone")
+	assert.Error(t, store.Reload(context.Background()))
+
+	source.err = nil
+	source.credentials = []ServiceCredential{{Service: "core-service", Keys: []ServiceKey{{ID: "bad", Hash: "not-hex"}}}}
+	assert.Error(t, store.Reload(context.Background()))
+
+	_, err := store.Verify("core-service", "key-1")
+	assert.NoError(t, err)
+}
+
+func TestFileSource_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.yml")
+	content := `services:
+  - service: core-service
+    keys:
+      - id: core-1
+        hash: ` + HashKey("key-1") + `
+        expires_at: 2099-01-01T00:00:00Z
+        scopes: [payments:read]
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	credentials, err := NewFileSource(path).Load(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	assert.Equal(t, "core-service", credentials[0].Service)
+	assert.Equal(t, []string{"payments:read"}, credentials[0].Keys[0].Scopes)
+	assert.Equal(t, 2099, credentials[0].Keys[0].ExpiresAt.Year())
+}
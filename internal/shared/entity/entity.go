@@ -5,7 +5,7 @@ import (
 )
 
 type Entity struct {
-	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id" bson:"_id"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
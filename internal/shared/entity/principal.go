@@ -0,0 +1,43 @@
+package entity
+
+type PrincipalKind string
+
+const (
+	PrincipalService PrincipalKind = "service"
+	PrincipalUser    PrincipalKind = "user"
+)
+
+// Principal is the authenticated caller of a request: a service holding an
+// API key or a user holding a JWT. Exactly one of Service and User is set.
+type Principal struct {
+	Kind    PrincipalKind
+	Service *ServiceClaims
+	User    *CustomClaims
+}
+
+func NewServicePrincipal(claims *ServiceClaims) *Principal {
+	return &Principal{Kind: PrincipalService, Service: claims}
+}
+
+func NewUserPrincipal(claims *CustomClaims) *Principal {
+	return &Principal{Kind: PrincipalUser, User: claims}
+}
+
+// ID is the service name or the user ID.
+func (p *Principal) ID() string {
+	switch {
+	case p.Service != nil:
+		return p.Service.ServiceName
+	case p.User != nil:
+		return p.User.UserID
+	}
+	return ""
+}
+
+func (p *Principal) IsService() bool {
+	return p.Kind == PrincipalService
+}
+
+func (p *Principal) IsUser() bool {
+	return p.Kind == PrincipalUser
+}
@@ -0,0 +1,8 @@
+package entity
+
+// ServiceClaims identifies a calling service authenticated by API key.
+type ServiceClaims struct {
+	ServiceName string   `json:"service_name"`
+	KeyID       string   `json:"key_id,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+}
@@ -1,8 +1,37 @@
 package errors
 
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Codes are stable, machine-readable identifiers returned in ErrorDTO.Code.
+// Clients should branch on them instead of on the messages.
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeValidation          = "validation_failed"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeRateLimited         = "rate_limited"
+	CodeProviderError       = "provider_error"
+	CodeProviderValidation  = "provider_validation_failed"
+	CodeProviderUnavailable = "provider_unavailable"
+	CodeUnavailable         = "service_unavailable"
+	CodeTimeout             = "timeout"
+	CodeInternal            = "internal_error"
+)
+
 type ErrorDTO struct {
+	Code         string `json:"code"`
 	Message      string `json:"message"`
 	MessageError string `json:"message_error"`
+	RequestID    string `json:"request_id,omitempty"`
+	// Causes lists what the payment provider found wrong with the request,
+	// for provider_validation_failed.
+	Causes []ProviderErrorCause `json:"causes,omitempty"`
 }
 
 type ValidationError struct {
@@ -36,3 +65,95 @@ type NotFoundError struct {
 func (e *NotFoundError) Error() string {
 	return e.Msg
 }
+
+type ConflictError struct {
+	Msg string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Msg
+}
+
+type ForbiddenError struct {
+	Msg string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Msg
+}
+
+// RateLimitedError means the caller exceeded its budget. RetryAfter, when
+// set, is sent back in the Retry-After header.
+type RateLimitedError struct {
+	Msg        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return e.Msg
+}
+
+// UnavailableError means this service cannot serve the request right now,
+// e.g. while shutting down or when a required component is not ready.
+type UnavailableError struct {
+	Msg string
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Msg
+}
+
+type TimeoutError struct {
+	Msg string
+}
+
+func (e *TimeoutError) Error() string {
+	return e.Msg
+}
+
+// ProviderUnavailableError means an external provider could not be reached,
+// timed out or answered with 5xx/429 after retries.
+type ProviderUnavailableError struct {
+	Provider string
+	Msg      string
+}
+
+func (e *ProviderUnavailableError) Error() string {
+	return e.Provider + " unavailable: " + e.Msg
+}
+
+// ProviderError is a request rejected by an external provider (4xx other
+// than 429). Code and Causes carry the provider's own error identifiers.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Code       string
+	Msg        string
+	Causes     []ProviderErrorCause
+}
+
+type ProviderErrorCause struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// InvalidRequest reports whether the provider rejected the data we sent
+// (400 or 422), which the caller may be able to fix, rather than failing on
+// its side or on our credentials.
+func (e *ProviderError) InvalidRequest() bool {
+	return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+}
+
+func (e *ProviderError) Error() string {
+	msg := fmt.Sprintf("%s returned %d", e.Provider, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Msg != "" {
+		msg += ": " + e.Msg
+	}
+	for _, cause := range e.Causes {
+		msg += fmt.Sprintf(" [%s: %s]", cause.Code, cause.Description)
+	}
+	return msg
+}
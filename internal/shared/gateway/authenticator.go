@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// Authenticator validates the credentials presented to this service: user
+// JWTs and service API keys. Both auth middlewares depend on it.
+type Authenticator interface {
+	ValidateToken(ctx context.Context, token string) (*entity.CustomClaims, error)
+	ValidateServiceToken(ctx context.Context, apiKey, serviceName string) (*entity.ServiceClaims, error)
+}
+
+// ErrInvalidToken marks a token that was checked and rejected, as opposed to
+// a validation that could not be completed.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Wire contract with the auth Lambda. Both endpoints take a JSON POST and
+// answer 200 with valid=true when the credential is accepted, or 401/403
+// (or 200 with valid=false) and an error message when it is rejected.
+//
+//	POST <token_url>    {"token": "..."}
+//	                    -> {"valid": true, "claims": {"user_id": "...", "user_type": "...", "exp": 0, ...}}
+//	POST <service_url>  {"serviceName": "...", "apiKey": "..."}
+//	                    -> {"valid": true, "scopes": ["..."]}
+
+// TokenRequest represents the request payload for token validation
+type TokenRequest struct {
+	Token string `json:"token"`
+}
+
+// TokenResponse represents the response from Lambda auth validation
+type TokenResponse struct {
+	Valid  bool                 `json:"valid"`
+	Claims *entity.CustomClaims `json:"claims,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// ServiceTokenRequest represents the request payload for service API key
+// validation
+type ServiceTokenRequest struct {
+	ServiceName string `json:"serviceName"`
+	APIKey      string `json:"apiKey"`
+}
+
+// ServiceTokenResponse represents the response from Lambda service validation
+type ServiceTokenResponse struct {
+	Valid  bool     `json:"valid"`
+	Scopes []string `json:"scopes,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
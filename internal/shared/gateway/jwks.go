@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksMinRefetch bounds how often an unknown kid can trigger a refetch, so
+// tokens with made-up key IDs cannot be used to hammer the JWKS endpoint.
+const jwksMinRefetch = time.Minute
+
+// jwksFailureBackoff is how long a failed fetch is reported before the
+// endpoint is tried again.
+const jwksFailureBackoff = 10 * time.Second
+
+// errUnknownKeyID marks a token signed with a key we do not publish, which
+// is the token's fault rather than an outage.
+var errUnknownKeyID = errors.New("unknown key id")
+
+// JWKSKeySource fetches and caches the public keys published at a JWKS URL.
+// Keys are refreshed after the refresh interval or when a token references
+// an unknown kid, which covers key rotation. The fetch runs outside the lock
+// and concurrent lookups wait for the same one, so validations of cached
+// keys never queue behind the endpoint.
+type JWKSKeySource struct {
+	url        string
+	refresh    time.Duration
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	// fetching is closed when the running fetch ends; nil when none runs.
+	fetching chan struct{}
+	now      func() time.Time
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func NewJWKSKeySource(url string, refresh time.Duration) *JWKSKeySource {
+	return &JWKSKeySource{
+		url:     url,
+		refresh: refresh,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		now: time.Now,
+	}
+}
+
+// Key returns the public key for kid. A cached key is served even while the
+// endpoint is down; other lookups report the failed fetch until
+// jwksFailureBackoff has passed.
+func (s *JWKSKeySource) Key(kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	for s.shouldFetch(kid) {
+		if s.fetching != nil {
+			done := s.fetching
+			s.mu.Unlock()
+			<-done
+			s.mu.Lock()
+			continue
+		}
+
+		done := make(chan struct{})
+		s.fetching = done
+		s.mu.Unlock()
+
+		keys, err := s.fetch()
+
+		s.mu.Lock()
+		s.attemptedAt = s.now()
+		s.fetchErr = err
+		if err == nil {
+			s.keys = keys
+			s.fetchedAt = s.attemptedAt
+		}
+		s.fetching = nil
+		close(done)
+	}
+	defer s.mu.Unlock()
+
+	if key, found := s.keys[kid]; found {
+		return key, nil
+	}
+	if s.fetchErr != nil {
+		return nil, s.fetchErr
+	}
+	return nil, fmt.Errorf("%w %q", errUnknownKeyID, kid)
+}
+
+// shouldFetch reports whether kid needs a fetch: the keys are stale, or kid
+// is unknown. Attempts are spaced by jwksMinRefetch, or jwksFailureBackoff
+// after a failure. Called with s.mu held.
+func (s *JWKSKeySource) shouldFetch(kid string) bool {
+	now := s.now()
+	_, found := s.keys[kid]
+	stale := s.keys == nil || now.Sub(s.fetchedAt) > s.refresh
+	if !stale && found {
+		return false
+	}
+	if s.attemptedAt.IsZero() {
+		return true
+	}
+
+	wait := jwksMinRefetch
+	if s.fetchErr != nil {
+		wait = jwksFailureBackoff
+	}
+	return now.Sub(s.attemptedAt) >= wait
+}
+
+func (s *JWKSKeySource) fetch() (map[string]crypto.PublicKey, error) {
+	resp, err := s.httpClient.Get(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// One key we cannot use must not reject tokens signed by the others.
+			slog.Warn("skipping jwk", "kid", k.Kid, "kty", k.Kty, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
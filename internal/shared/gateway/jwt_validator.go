@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// JWTConfig selects the keys used to verify tokens locally. Any combination
+// may be set; only the algorithms backed by a key are accepted.
+type JWTConfig struct {
+	// JWKSURL enables RS256/ES256 with keys looked up by kid.
+	JWKSURL     string
+	JWKSRefresh time.Duration
+	// PublicKeyPEM enables RS256 or ES256, depending on the key type.
+	PublicKeyPEM []byte
+	// HMACSecret enables HS256.
+	HMACSecret []byte
+	// Leeway tolerates clock skew when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// JWTValidator verifies tokens in-process, avoiding a Lambda round trip per
+// request.
+type JWTValidator struct {
+	jwks      *JWKSKeySource
+	publicKey crypto.PublicKey
+	secret    []byte
+	parser    *jwt.Parser
+}
+
+func NewJWTValidator(config JWTConfig) (*JWTValidator, error) {
+	validator := &JWTValidator{secret: config.HMACSecret}
+	var methods []string
+
+	if config.JWKSURL != "" {
+		validator.jwks = NewJWKSKeySource(config.JWKSURL, config.JWKSRefresh)
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	if len(config.PublicKeyPEM) > 0 {
+		key, alg, err := parsePublicKey(config.PublicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		validator.publicKey = key
+		methods = appendMissing(methods, alg)
+	}
+
+	if len(config.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if len(methods) == 0 {
+		return nil, errors.New("jwt validation requires a jwks url, a public key or a shared secret")
+	}
+
+	validator.parser = jwt.NewParser(
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(config.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	return validator, nil
+}
+
+// ValidateToken checks the signature and the exp, nbf and iat claims.
+func (v *JWTValidator) ValidateToken(tokenString string) (*entity.CustomClaims, error) {
+	if tokenString == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+
+	var claims jwtClaims
+	if _, err := v.parser.ParseWithClaims(tokenString, &claims, v.key); err != nil {
+		if errors.Is(err, jwt.ErrTokenUnverifiable) && !errors.Is(err, errUnknownKeyID) {
+			// The key could not be resolved (e.g. JWKS down); the token
+			// itself may be fine.
+			return nil, fmt.Errorf("failed to verify token: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return &claims.CustomClaims, nil
+}
+
+func (v *JWTValidator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.secret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if kid, ok := token.Header["kid"].(string); ok && v.jwks != nil {
+			key, err := v.jwks.Key(kid)
+			// A kid the JWKS does not publish may still belong to the
+			// configured PEM key, e.g. while issuers move to the JWKS.
+			if !errors.Is(err, errUnknownKeyID) || v.publicKey == nil {
+				return key, err
+			}
+		}
+		if v.publicKey != nil {
+			return v.publicKey, nil
+		}
+		return nil, fmt.Errorf("%w: token has no kid", errUnknownKeyID)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, string, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodRS256.Alg(), nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodES256.Alg(), nil
+	}
+	return nil, "", errors.New("public key must be an RSA or ECDSA PEM")
+}
+
+func appendMissing(methods []string, method string) []string {
+	for _, m := range methods {
+		if m == method {
+			return methods
+		}
+	}
+	return append(methods, method)
+}
+
+// jwtClaims adapts entity.CustomClaims to jwt.Claims so the parser validates
+// its registered time claims.
+type jwtClaims struct {
+	entity.CustomClaims
+}
+
+func (c jwtClaims) GetExpirationTime() (*jwt.NumericDate, error) {
+	return numericDate(c.ExpiresAt), nil
+}
+
+func (c jwtClaims) GetIssuedAt() (*jwt.NumericDate, error) {
+	return numericDate(c.IssuedAt), nil
+}
+
+func (c jwtClaims) GetNotBefore() (*jwt.NumericDate, error) {
+	return numericDate(c.NotBefore), nil
+}
+
+func (c jwtClaims) GetIssuer() (string, error) {
+	return "", nil
+}
+
+func (c jwtClaims) GetSubject() (string, error) {
+	return c.UserID, nil
+}
+
+func (c jwtClaims) GetAudience() (jwt.ClaimStrings, error) {
+	return nil, nil
+}
+
+func numericDate(unix int64) *jwt.NumericDate {
+	if unix == 0 {
+		return nil
+	}
+	return jwt.NewNumericDate(time.Unix(unix, 0))
+}
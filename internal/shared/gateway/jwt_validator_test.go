@@ -0,0 +1,308 @@
+package gateway
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testSecret)
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTValidator_HS256(t *testing.T) {
+	validator, err := NewJWTValidator(JWTConfig{HMACSecret: testSecret})
+	require.NoError(t, err)
+
+	now := time.Now()
+	tests := []struct {
+		name        string
+		claims      jwt.MapClaims
+		expectError bool
+	}{
+		{
+			name:   "Given a valid token, it should return its claims",
+			claims: jwt.MapClaims{"user_id": "u-1", "user_type": "admin", "exp": now.Add(time.Hour).Unix(), "iat": now.Unix()},
+		},
+		{
+			name:        "Given an expired token, it should fail",
+			claims:      jwt.MapClaims{"user_id": "u-1", "exp": now.Add(-time.Hour).Unix()},
+			expectError: true,
+		},
+		{
+			name:        "Given a token without exp, it should fail",
+			claims:      jwt.MapClaims{"user_id": "u-1"},
+			expectError: true,
+		},
+		{
+			name:        "Given a token not valid yet, it should fail",
+			claims:      jwt.MapClaims{"user_id": "u-1", "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Minute).Unix()},
+			expectError: true,
+		},
+		{
+			name:        "Given a token issued in the future, it should fail",
+			claims:      jwt.MapClaims{"user_id": "u-1", "exp": now.Add(time.Hour).Unix(), "iat": now.Add(time.Minute).Unix()},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := validator.ValidateToken(signHS256(t, tt.claims))
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "u-1", claims.UserID)
+			assert.Equal(t, "admin", claims.UserType)
+		})
+	}
+}
+
+func TestJWTValidator_Leeway(t *testing.T) {
+	validator, err := NewJWTValidator(JWTConfig{HMACSecret: testSecret, Leeway: time.Minute})
+	require.NoError(t, err)
+
+	_, err = validator.ValidateToken(signHS256(t, jwt.MapClaims{"exp": time.Now().Add(-30 * time.Second).Unix()}))
+
+	assert.NoError(t, err)
+}
+
+func TestJWTValidator_RS256WithPEM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	validator, err := NewJWTValidator(JWTConfig{
+		PublicKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+	})
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"user_id": "u-2",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	claims, err := validator.ValidateToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "u-2", claims.UserID)
+
+	// HS256 is not enabled, so a token signed with anything else is rejected.
+	_, err = validator.ValidateToken(signHS256(t, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}))
+	assert.Error(t, err)
+}
+
+func TestJWTValidator_ES256WithJWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{{
+			Kid: "key-1",
+			Kty: "EC",
+			Use: "sig",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	}))
+	defer server.Close()
+
+	validator, err := NewJWTValidator(JWTConfig{JWKSURL: server.URL, JWKSRefresh: time.Hour})
+	require.NoError(t, err)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+			"user_id": "u-3",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	claims, err := validator.ValidateToken(sign("key-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "u-3", claims.UserID)
+
+	_, err = validator.ValidateToken(sign("key-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches, "keys should be cached")
+
+	_, err = validator.ValidateToken(sign("unknown"))
+	assert.ErrorIs(t, err, ErrInvalidToken, "an unknown kid is a bad token, not an outage")
+	assert.Equal(t, 1, fetches, "unknown kids should not refetch before the minimum interval")
+}
+
+func TestJWTValidator_JWKSWithPEMFallback(t *testing.T) {
+	jwksKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pemKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{{
+			Kid: "key-1",
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(jwksKey.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(jwksKey.Y.FillBytes(make([]byte, 32))),
+		}}})
+	}))
+	defer server.Close()
+
+	der, err := x509.MarshalPKIXPublicKey(&pemKey.PublicKey)
+	require.NoError(t, err)
+	validator, err := NewJWTValidator(JWTConfig{
+		JWKSURL:      server.URL,
+		JWKSRefresh:  time.Hour,
+		PublicKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+	})
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{
+			"user_id": "u-4",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	_, err = validator.ValidateToken(sign(jwt.SigningMethodES256, "key-1", jwksKey))
+	assert.NoError(t, err, "keys published in the JWKS should still be used")
+
+	claims, err := validator.ValidateToken(sign(jwt.SigningMethodRS256, "legacy", pemKey))
+	require.NoError(t, err, "a kid missing from the JWKS should fall back to the PEM key")
+	assert.Equal(t, "u-4", claims.UserID)
+
+	_, err = validator.ValidateToken(sign(jwt.SigningMethodRS256, "legacy", otherKey))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewJWTValidator_RequiresKey(t *testing.T) {
+	_, err := NewJWTValidator(JWTConfig{})
+
+	assert.Error(t, err)
+}
+
+func TestJWKSKeySource_SkipsUnsupportedKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{
+			{Kid: "ed25519", Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+			{
+				Kid: "key-1",
+				Kty: "EC",
+				Crv: "P-256",
+				X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+				Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+			},
+		}})
+	}))
+	defer server.Close()
+
+	source := NewJWKSKeySource(server.URL, time.Hour)
+
+	_, err = source.Key("key-1")
+	assert.NoError(t, err, "a key we cannot use should not reject the others")
+	_, err = source.Key("ed25519")
+	assert.ErrorIs(t, err, errUnknownKeyID)
+}
+
+func TestJWKSKeySource_BacksOffAfterFailure(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	source := NewJWKSKeySource(server.URL, time.Hour)
+	source.now = func() time.Time { return now }
+
+	_, err := source.Key("key-1")
+	assert.Error(t, err)
+	_, err = source.Key("key-1")
+	assert.Error(t, err, "the failed fetch should be reported while backing off")
+	assert.NotErrorIs(t, err, errUnknownKeyID)
+	assert.Equal(t, int32(1), fetches.Load(), "a failed fetch should not be retried on every request")
+
+	now = now.Add(jwksFailureBackoff)
+	_, err = source.Key("key-1")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "the endpoint should be retried after the backoff")
+}
+
+func TestJWKSKeySource_CoalescesConcurrentFetches(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{{
+			Kid: "key-1",
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}}})
+	}))
+	defer server.Close()
+
+	source := NewJWKSKeySource(server.URL, time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = source.Key("key-1")
+		}()
+	}
+	require.Eventually(t, func() bool { return fetches.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), fetches.Load(), "concurrent lookups should share one fetch")
+}
@@ -2,90 +2,159 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/credentials"
 	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/logger"
 )
 
-// ServerlessAuthGateway implements authentication via AWS Lambda functions
+const defaultAuthTimeout = 10 * time.Second
+
+// ServerlessAuthGateway implements Authenticator via AWS Lambda functions,
+// optionally validating JWTs locally and caching results.
 // Following the same pattern as JWTService from tc-golunch-api monolith
 type ServerlessAuthGateway struct {
-	lambdaAuthURL      string
-	serviceAuthURL     string
-	httpClient         *http.Client
+	tokenURL     string
+	serviceURL   string
+	httpClient   *http.Client
+	jwtValidator *JWTValidator
+	tokenCache   *TokenCache
+	credentials  *credentials.Store
 }
 
-// TokenRequest represents the request payload for token validation
-type TokenRequest struct {
-	Token string `json:"token"`
+var _ Authenticator = (*ServerlessAuthGateway)(nil)
+
+// Option customizes a ServerlessAuthGateway.
+type Option func(*ServerlessAuthGateway)
+
+// WithLocalValidation verifies user tokens in-process instead of calling the
+// Lambda, which is then only used for service credentials.
+func WithLocalValidation(validator *JWTValidator) Option {
+	return func(s *ServerlessAuthGateway) {
+		s.jwtValidator = validator
+	}
 }
 
-// TokenResponse represents the response from Lambda auth validation
-type TokenResponse struct {
-	Valid   bool                   `json:"valid"`
-	Claims  *entity.CustomClaims   `json:"claims,omitempty"`
-	Error   string                 `json:"error,omitempty"`
+// WithTokenCache caches validation results so repeated requests with the
+// same token skip the validator.
+func WithTokenCache(cache *TokenCache) Option {
+	return func(s *ServerlessAuthGateway) {
+		s.tokenCache = cache
+	}
+}
+
+// WithServiceCredentials verifies service API keys against the local
+// credential store. Services missing from the store still go to the Lambda
+// when a service URL is configured.
+func WithServiceCredentials(store *credentials.Store) Option {
+	return func(s *ServerlessAuthGateway) {
+		s.credentials = store
+	}
+}
+
+// WithTimeout bounds each call to the Lambda.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *ServerlessAuthGateway) {
+		if timeout > 0 {
+			s.httpClient.Timeout = timeout
+		}
+	}
 }
 
-// NewServerlessAuthGateway creates a new serverless authentication gateway
+// WithTransport wraps the transport used to call the Lambda, e.g. to
+// instrument calls.
+func WithTransport(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(s *ServerlessAuthGateway) {
+		s.httpClient.Transport = wrap(s.httpClient.Transport)
+	}
+}
+
+// NewServerlessAuthGateway creates a new serverless authentication gateway.
+// tokenURL and serviceURL are the full endpoint URLs; an empty URL makes the
+// matching validation fail instead of falling back to a default.
 // Similar to NewJWTService from tc-golunch-api
-func NewServerlessAuthGateway(lambdaAuthURL, serviceAuthURL string) *ServerlessAuthGateway {
-	return &ServerlessAuthGateway{
-		lambdaAuthURL:  lambdaAuthURL,
-		serviceAuthURL: serviceAuthURL,
+func NewServerlessAuthGateway(tokenURL, serviceURL string, opts ...Option) *ServerlessAuthGateway {
+	gateway := &ServerlessAuthGateway{
+		tokenURL:   tokenURL,
+		serviceURL: serviceURL,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   defaultAuthTimeout,
+			Transport: &logger.Transport{},
 		},
 	}
+	for _, opt := range opts {
+		opt(gateway)
+	}
+	return gateway
 }
 
-// ValidateToken validates JWT token via AWS Lambda ServiceAuth function
+// ValidateToken validates JWT token locally when configured, otherwise via
+// AWS Lambda ServiceAuth function
 // Maintains same interface as JWTService.ValidateToken from tc-golunch-api
-func (s *ServerlessAuthGateway) ValidateToken(tokenString string) (*entity.CustomClaims, error) {
+func (s *ServerlessAuthGateway) ValidateToken(ctx context.Context, tokenString string) (*entity.CustomClaims, error) {
 	if tokenString == "" {
 		return nil, fmt.Errorf("token is required")
 	}
 
-	// Prepare request payload
-	requestPayload := TokenRequest{
-		Token: tokenString,
+	if s.tokenCache == nil {
+		return s.validateToken(ctx, tokenString)
 	}
 
-	jsonData, err := json.Marshal(requestPayload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	if claims, found, err := s.tokenCache.Get(tokenString); found {
+		return claims, err
 	}
 
-	// Call Lambda ServiceAuth function
-	req, err := http.NewRequest("POST", s.serviceAuthURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	claims, err := s.validateToken(ctx, tokenString)
+	switch {
+	case err == nil:
+		s.tokenCache.Set(tokenString, claims)
+	case errors.Is(err, ErrInvalidToken):
+		s.tokenCache.SetInvalid(tokenString, err)
 	}
+	return claims, err
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "GoLunch-Payment-Service/1.0")
+// TokenCacheStats reports the validation cache counters; ok is false when
+// caching is disabled.
+func (s *ServerlessAuthGateway) TokenCacheStats() (stats TokenCacheStats, ok bool) {
+	if s.tokenCache == nil {
+		return TokenCacheStats{}, false
+	}
+	return s.tokenCache.Stats(), true
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call serverless auth: %v", err)
+func (s *ServerlessAuthGateway) validateToken(ctx context.Context, tokenString string) (*entity.CustomClaims, error) {
+	if s.jwtValidator != nil {
+		return s.jwtValidator.ValidateToken(tokenString)
+	}
+
+	if s.tokenURL == "" {
+		return nil, errors.New("auth token endpoint is not configured")
 	}
-	defer resp.Body.Close()
 
 	var tokenResponse TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	status, err := s.post(ctx, s.tokenURL, TokenRequest{Token: tokenString}, &tokenResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call serverless auth: %w", err)
 	}
 
 	// Handle different response statuses
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("serverless auth error: %s", tokenResponse.Error)
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, tokenResponse.Error)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("serverless auth error: status %d: %s", status, tokenResponse.Error)
 	}
 
 	if !tokenResponse.Valid {
-		return nil, fmt.Errorf("invalid token: %s", tokenResponse.Error)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, tokenResponse.Error)
 	}
 
 	if tokenResponse.Claims == nil {
@@ -97,26 +166,49 @@ func (s *ServerlessAuthGateway) ValidateToken(tokenString string) (*entity.Custo
 
 // ValidateServiceToken validates API key for service-to-service communication
 // New method specific to microservices architecture
-func (s *ServerlessAuthGateway) ValidateServiceToken(apiKey, serviceName string) (bool, error) {
+func (s *ServerlessAuthGateway) ValidateServiceToken(ctx context.Context, apiKey, serviceName string) (*entity.ServiceClaims, error) {
 	if apiKey == "" || serviceName == "" {
-		return false, fmt.Errorf("api key and service name are required")
+		return nil, fmt.Errorf("api key and service name are required")
 	}
 
-	// Prepare service validation request
-	serviceRequest := map[string]string{
-		"apiKey":      apiKey,
-		"serviceName": serviceName,
+	if s.credentials != nil {
+		claims, err := s.credentials.Verify(serviceName, apiKey)
+		if !errors.Is(err, credentials.ErrUnknownService) || s.serviceURL == "" {
+			return claims, err
+		}
 	}
 
-	jsonData, err := json.Marshal(serviceRequest)
+	if s.serviceURL == "" {
+		return nil, errors.New("auth service endpoint is not configured")
+	}
+
+	var serviceResponse ServiceTokenResponse
+	status, err := s.post(ctx, s.serviceURL, ServiceTokenRequest{ServiceName: serviceName, APIKey: apiKey}, &serviceResponse)
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal service request: %v", err)
+		return nil, fmt.Errorf("failed to call service auth: %w", err)
 	}
 
-	// Call Lambda ServiceAuth for service validation
-	req, err := http.NewRequest("POST", s.serviceAuthURL+"/validate-service", bytes.NewBuffer(jsonData))
+	switch {
+	case status == http.StatusOK && serviceResponse.Valid:
+		return &entity.ServiceClaims{ServiceName: serviceName, Scopes: serviceResponse.Scopes}, nil
+	case status == http.StatusOK, status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return nil, credentials.ErrInvalidKey
+	default:
+		return nil, fmt.Errorf("service auth error: status %d: %s", status, serviceResponse.Error)
+	}
+}
+
+// post sends body as JSON and decodes the answer into result. Error statuses
+// are returned to the caller, which decides what they mean.
+func (s *ServerlessAuthGateway) post(ctx context.Context, url string, body, result interface{}) (int, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return false, fmt.Errorf("failed to create service request: %v", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -124,9 +216,19 @@ func (s *ServerlessAuthGateway) ValidateServiceToken(apiKey, serviceName string)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("failed to call service auth: %v", err)
+		return 0, err
 	}
 	defer resp.Body.Close()
 
-	return resp.StatusCode == http.StatusOK, nil
-}
\ No newline at end of file
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, result); err != nil && resp.StatusCode == http.StatusOK {
+			return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/credentials"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+func TestServerlessAuthGateway_ValidateServiceToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		response    ServiceTokenResponse
+		expectError bool
+	}{
+		{
+			name:       "Given an accepted key, it should be valid",
+			statusCode: http.StatusOK,
+			response:   ServiceTokenResponse{Valid: true, Scopes: []string{"payments:read"}},
+		},
+		{
+			name:        "Given a rejected key, it should be invalid",
+			statusCode:  http.StatusUnauthorized,
+			response:    ServiceTokenResponse{Error: "unknown key"},
+			expectError: true,
+		},
+		{
+			name:        "Given a Lambda failure, it should return an error",
+			statusCode:  http.StatusInternalServerError,
+			response:    ServiceTokenResponse{Error: "boom"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received ServiceTokenRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.statusCode)
+				json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			gateway := NewServerlessAuthGateway("", server.URL)
+			claims, err := gateway.ValidateServiceToken(context.Background(), "key-1", "core-service")
+
+			assert.Equal(t, ServiceTokenRequest{ServiceName: "core-service", APIKey: "key-1"}, received)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &entity.ServiceClaims{ServiceName: "core-service", Scopes: []string{"payments:read"}}, claims)
+		})
+	}
+}
+
+func TestServerlessAuthGateway_RequiresConfiguredEndpoints(t *testing.T) {
+	gateway := NewServerlessAuthGateway("", "")
+
+	_, err := gateway.ValidateToken(context.Background(), "token")
+	assert.EqualError(t, err, "auth token endpoint is not configured")
+
+	_, err = gateway.ValidateServiceToken(context.Background(), "key", "core-service")
+	assert.EqualError(t, err, "auth service endpoint is not configured")
+}
+
+type staticCredentials []credentials.ServiceCredential
+
+func (s staticCredentials) Load(ctx context.Context) ([]credentials.ServiceCredential, error) {
+	return s, nil
+}
+
+func TestServerlessAuthGateway_ValidateServiceTokenWithCredentials(t *testing.T) {
+	lambdaCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lambdaCalls++
+		json.NewEncoder(w).Encode(ServiceTokenResponse{Valid: true})
+	}))
+	defer server.Close()
+
+	store := credentials.NewStore(staticCredentials{
+		{Service: "core-service", Keys: []credentials.ServiceKey{{ID: "core-1", Hash: credentials.HashKey("key-1")}}},
+	})
+	assert.NoError(t, store.Reload(context.Background()))
+	gateway := NewServerlessAuthGateway("", server.URL, WithServiceCredentials(store))
+
+	claims, err := gateway.ValidateServiceToken(context.Background(), "key-1", "core-service")
+	assert.NoError(t, err)
+	assert.Equal(t, "core-1", claims.KeyID)
+
+	_, err = gateway.ValidateServiceToken(context.Background(), "wrong", "core-service")
+	assert.ErrorIs(t, err, credentials.ErrInvalidKey)
+	assert.Equal(t, 0, lambdaCalls, "services known locally never reach the Lambda")
+
+	_, err = gateway.ValidateServiceToken(context.Background(), "key-2", "operation-service")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lambdaCalls)
+}
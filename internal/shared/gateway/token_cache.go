@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// TokenCacheSettings bounds the validation cache. NegativeTTL applies to
+// tokens the validator rejected; zero disables negative caching.
+type TokenCacheSettings struct {
+	MaxEntries  int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
+// TokenCacheStats is a snapshot of the cache counters.
+type TokenCacheStats struct {
+	Hits         uint64  `json:"hits"`
+	NegativeHits uint64  `json:"negative_hits"`
+	Misses       uint64  `json:"misses"`
+	Entries      int     `json:"entries"`
+	HitRatio     float64 `json:"hit_ratio"`
+}
+
+// TokenCache keeps validation results keyed by the SHA-256 of the token, so
+// raw tokens are never held in memory longer than the request. Entries live
+// for the TTL, never past the token's exp, and the least recently used entry
+// is evicted once MaxEntries is reached.
+type TokenCache struct {
+	settings TokenCacheSettings
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	now     func() time.Time
+
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+}
+
+type tokenCacheEntry struct {
+	key       string
+	claims    *entity.CustomClaims
+	err       error
+	expiresAt time.Time
+}
+
+func NewTokenCache(settings TokenCacheSettings) *TokenCache {
+	return &TokenCache{
+		settings: settings,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the cached result for token. A cached rejection is returned as
+// nil claims with its original error.
+func (c *TokenCache) Get(token string) (*entity.CustomClaims, bool, error) {
+	key := tokenKey(token)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		c.misses.Add(1)
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*tokenCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		c.misses.Add(1)
+		return nil, false, nil
+	}
+
+	c.lru.MoveToFront(element)
+	if entry.err != nil {
+		c.negativeHits.Add(1)
+		return nil, true, entry.err
+	}
+	c.hits.Add(1)
+	claims := *entry.claims
+	return &claims, true, nil
+}
+
+// Set caches valid claims until the TTL or the token's exp, whichever comes
+// first.
+func (c *TokenCache) Set(token string, claims *entity.CustomClaims) {
+	now := c.now()
+	expiresAt := now.Add(c.settings.TTL)
+	if claims.ExpiresAt != 0 {
+		if exp := time.Unix(claims.ExpiresAt, 0); exp.Before(expiresAt) {
+			expiresAt = exp
+		}
+	}
+	if !now.Before(expiresAt) {
+		return
+	}
+
+	stored := *claims
+	c.put(&tokenCacheEntry{key: tokenKey(token), claims: &stored, expiresAt: expiresAt})
+}
+
+// SetInvalid caches a rejection for NegativeTTL so replayed bad tokens do
+// not reach the validator on every request.
+func (c *TokenCache) SetInvalid(token string, err error) {
+	if c.settings.NegativeTTL <= 0 {
+		return
+	}
+	c.put(&tokenCacheEntry{key: tokenKey(token), err: err, expiresAt: c.now().Add(c.settings.NegativeTTL)})
+}
+
+func (c *TokenCache) Stats() TokenCacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	stats := TokenCacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Entries:      entries,
+	}
+	if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+	return stats
+}
+
+func (c *TokenCache) put(entry *tokenCacheEntry) {
+	if c.settings.MaxEntries <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[entry.key]; found {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.settings.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *TokenCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*tokenCacheEntry).key)
+}
+
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+func newTestCache(now *time.Time, settings TokenCacheSettings) *TokenCache {
+	cache := NewTokenCache(settings)
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func TestTokenCache_TTLCappedAtExp(t *testing.T) {
+	now := time.Now()
+	cache := newTestCache(&now, TokenCacheSettings{MaxEntries: 10, TTL: time.Hour})
+
+	cache.Set("token", &entity.CustomClaims{UserID: "u-1", ExpiresAt: now.Add(time.Minute).Unix()})
+
+	claims, found, err := cache.Get("token")
+	assert.True(t, found)
+	assert.NoError(t, err)
+	assert.Equal(t, "u-1", claims.UserID)
+
+	now = now.Add(2 * time.Minute)
+	_, found, _ = cache.Get("token")
+	assert.False(t, found, "entries must not outlive the token exp")
+}
+
+func TestTokenCache_NegativeCaching(t *testing.T) {
+	now := time.Now()
+	cache := newTestCache(&now, TokenCacheSettings{MaxEntries: 10, TTL: time.Hour, NegativeTTL: 30 * time.Second})
+	rejected := errors.New("invalid token: expired")
+
+	cache.SetInvalid("bad", rejected)
+
+	claims, found, err := cache.Get("bad")
+	assert.True(t, found)
+	assert.Nil(t, claims)
+	assert.Equal(t, rejected, err)
+
+	now = now.Add(time.Minute)
+	_, found, _ = cache.Get("bad")
+	assert.False(t, found)
+}
+
+func TestTokenCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	now := time.Now()
+	cache := newTestCache(&now, TokenCacheSettings{MaxEntries: 2, TTL: time.Hour})
+
+	cache.Set("a", &entity.CustomClaims{UserID: "a"})
+	cache.Set("b", &entity.CustomClaims{UserID: "b"})
+	cache.Get("a")
+	cache.Set("c", &entity.CustomClaims{UserID: "c"})
+
+	_, foundA, _ := cache.Get("a")
+	_, foundB, _ := cache.Get("b")
+	_, foundC, _ := cache.Get("c")
+	assert.True(t, foundA)
+	assert.False(t, foundB)
+	assert.True(t, foundC)
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, 0.75, stats.HitRatio)
+}
+
+func TestServerlessAuthGateway_ValidateTokenUsesCache(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var request TokenRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Token != "good" {
+			json.NewEncoder(w).Encode(TokenResponse{Valid: false, Error: "expired"})
+			return
+		}
+		json.NewEncoder(w).Encode(TokenResponse{Valid: true, Claims: &entity.CustomClaims{UserID: "u-1"}})
+	}))
+	defer server.Close()
+
+	gateway := NewServerlessAuthGateway(server.URL, "", WithTokenCache(NewTokenCache(TokenCacheSettings{
+		MaxEntries:  10,
+		TTL:         time.Minute,
+		NegativeTTL: time.Minute,
+	})))
+
+	for i := 0; i < 3; i++ {
+		claims, err := gateway.ValidateToken(context.Background(), "good")
+		assert.NoError(t, err)
+		assert.Equal(t, "u-1", claims.UserID)
+
+		_, err = gateway.ValidateToken(context.Background(), "bad")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	}
+
+	assert.Equal(t, 2, calls)
+	stats, enabled := gateway.TokenCacheStats()
+	assert.True(t, enabled)
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.NegativeHits)
+}
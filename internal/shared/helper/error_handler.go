@@ -1,33 +1,107 @@
 package helper
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/logger"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/tracing"
+)
+
+const (
+	RequestIDHeader = logger.RequestIDHeader
+	RequestIDKey    = "request_id"
 )
 
 func HandleError(c *gin.Context, err error) {
 	status := http.StatusInternalServerError
+	code := apperror.CodeInternal
 	message := "Internal Server Error"
+	var causes []apperror.ProviderErrorCause
+
+	var (
+		validationErr          *apperror.ValidationError
+		unauthorizedErr        *apperror.UnauthorizedError
+		forbiddenErr           *apperror.ForbiddenError
+		notFoundErr            *apperror.NotFoundError
+		conflictErr            *apperror.ConflictError
+		rateLimitedErr         *apperror.RateLimitedError
+		providerErr            *apperror.ProviderError
+		providerUnavailableErr *apperror.ProviderUnavailableError
+		unavailableErr         *apperror.UnavailableError
+		timeoutErr             *apperror.TimeoutError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		status, code, message = http.StatusBadRequest, apperror.CodeValidation, "Validation failed"
+	case errors.As(err, &unauthorizedErr):
+		status, code, message = http.StatusUnauthorized, apperror.CodeUnauthorized, "Unauthorized"
+	case errors.As(err, &forbiddenErr):
+		status, code, message = http.StatusForbidden, apperror.CodeForbidden, "Forbidden"
+	case errors.As(err, &notFoundErr):
+		status, code, message = http.StatusNotFound, apperror.CodeNotFound, "Resource not found"
+	case errors.As(err, &conflictErr):
+		status, code, message = http.StatusConflict, apperror.CodeConflict, "Conflict"
+	case errors.As(err, &rateLimitedErr):
+		status, code, message = http.StatusTooManyRequests, apperror.CodeRateLimited, "Too Many Requests"
+		if rateLimitedErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitedErr.RetryAfter.Seconds()))))
+		}
+	case errors.As(err, &providerErr) && providerErr.InvalidRequest():
+		status, code, message = http.StatusUnprocessableEntity, apperror.CodeProviderValidation, "Payment provider rejected the request data"
+		causes = providerErr.Causes
+	case errors.As(err, &providerErr):
+		status, code, message = http.StatusBadGateway, apperror.CodeProviderError, "Payment provider rejected the request"
+	case errors.As(err, &providerUnavailableErr):
+		status, code, message = http.StatusServiceUnavailable, apperror.CodeProviderUnavailable, "Service Unavailable"
+	case errors.As(err, &unavailableErr):
+		status, code, message = http.StatusServiceUnavailable, apperror.CodeUnavailable, "Service Unavailable"
+	case errors.As(err, &timeoutErr), errors.Is(err, context.DeadlineExceeded):
+		status, code, message = http.StatusGatewayTimeout, apperror.CodeTimeout, "Timeout"
+	}
 
-	switch err.(type) {
-	case *apperror.ValidationError:
-		status = http.StatusBadRequest
-		message = "Validation failed"
-	case *apperror.UnauthorizedError:
-		status = http.StatusUnauthorized
-		message = "Unauthorized"
-	case *apperror.NotFoundError:
-		status = http.StatusBadRequest
-		message = "Invalid resource"
+	if status >= http.StatusInternalServerError {
+		tracing.RecordError(c.Request.Context(), err)
+		slog.ErrorContext(c.Request.Context(), "request failed", "status", status, "code", code, "error", err)
 	}
 
 	c.JSON(status, apperror.ErrorDTO{
+		Code:         code,
 		Message:      message,
 		MessageError: err.Error(),
+		RequestID:    RequestID(c),
+		Causes:       causes,
 	})
 
 	c.Abort()
 }
+
+// HandleBindError answers 400 for request bodies or parameters that could
+// not be bound.
+func HandleBindError(c *gin.Context, message string, err error) {
+	c.JSON(http.StatusBadRequest, apperror.ErrorDTO{
+		Code:         apperror.CodeInvalidRequest,
+		Message:      message,
+		MessageError: err.Error(),
+		RequestID:    RequestID(c),
+	})
+
+	c.Abort()
+}
+
+// RequestID returns the ID set on the context by the request ID middleware,
+// falling back to the inbound header.
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(RequestIDKey); id != "" {
+		return id
+	}
+	return c.GetHeader(RequestIDHeader)
+}
@@ -0,0 +1,106 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+)
+
+func TestHandleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"validation", &apperror.ValidationError{Msg: "invalid"}, http.StatusBadRequest, apperror.CodeValidation},
+		{"unauthorized", &apperror.UnauthorizedError{Msg: "no token"}, http.StatusUnauthorized, apperror.CodeUnauthorized},
+		{"forbidden", &apperror.ForbiddenError{Msg: "admin only"}, http.StatusForbidden, apperror.CodeForbidden},
+		{"not found", &apperror.NotFoundError{Msg: "Payment not found"}, http.StatusNotFound, apperror.CodeNotFound},
+		{"conflict", &apperror.ConflictError{Msg: "Payment is not pending"}, http.StatusConflict, apperror.CodeConflict},
+		{"rate limited", &apperror.RateLimitedError{Msg: "slow down"}, http.StatusTooManyRequests, apperror.CodeRateLimited},
+		{"provider error", &apperror.ProviderError{Provider: "mercadopago", StatusCode: 401}, http.StatusBadGateway, apperror.CodeProviderError},
+		{"provider validation", &apperror.ProviderError{Provider: "mercadopago", StatusCode: 400}, http.StatusUnprocessableEntity, apperror.CodeProviderValidation},
+		{"provider unprocessable", &apperror.ProviderError{Provider: "mercadopago", StatusCode: 422}, http.StatusUnprocessableEntity, apperror.CodeProviderValidation},
+		{"provider unavailable", &apperror.ProviderUnavailableError{Provider: "core"}, http.StatusServiceUnavailable, apperror.CodeProviderUnavailable},
+		{"unavailable", &apperror.UnavailableError{Msg: "shutting down"}, http.StatusServiceUnavailable, apperror.CodeUnavailable},
+		{"timeout", &apperror.TimeoutError{Msg: "too slow"}, http.StatusGatewayTimeout, apperror.CodeTimeout},
+		{"deadline exceeded", context.DeadlineExceeded, http.StatusGatewayTimeout, apperror.CodeTimeout},
+		{"wrapped error", fmt.Errorf("error checking payment: %w", &apperror.NotFoundError{Msg: "order not found"}), http.StatusNotFound, apperror.CodeNotFound},
+		{"unknown error", fmt.Errorf("boom"), http.StatusInternalServerError, apperror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set(RequestIDHeader, "req-123")
+
+			HandleError(c, tt.err)
+
+			var body apperror.ErrorDTO
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedCode, body.Code)
+			assert.Equal(t, tt.err.Error(), body.MessageError)
+			assert.Equal(t, "req-123", body.RequestID)
+			assert.True(t, c.IsAborted())
+		})
+	}
+}
+
+func TestHandleError_ProviderValidationCauses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	causes := []apperror.ProviderErrorCause{{Code: "2067", Description: "Invalid user identification number"}}
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		expectedCauses []apperror.ProviderErrorCause
+	}{
+		{name: "Given a provider validation error, it should return its causes", statusCode: http.StatusBadRequest, expectedCauses: causes},
+		{name: "Given a provider credentials error, it should not expose its causes", statusCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/payments", nil)
+
+			HandleError(c, fmt.Errorf("failed to create payment: %w", &apperror.ProviderError{
+				Provider:   "mercadopago",
+				StatusCode: tt.statusCode,
+				Code:       "bad_request",
+				Causes:     causes,
+			}))
+
+			var body apperror.ErrorDTO
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedCauses, body.Causes)
+		})
+	}
+}
+
+func TestHandleError_RetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	HandleError(c, &apperror.RateLimitedError{Msg: "slow down", RetryAfter: 1500 * time.Millisecond})
+
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+}
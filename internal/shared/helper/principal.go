@@ -0,0 +1,21 @@
+package helper
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// PrincipalKey is where the auth middlewares store the *entity.Principal.
+const PrincipalKey = "principal"
+
+func SetPrincipal(c *gin.Context, principal *entity.Principal) {
+	c.Set(PrincipalKey, principal)
+}
+
+// Principal returns the authenticated caller, if the route is authenticated.
+func Principal(c *gin.Context) (*entity.Principal, bool) {
+	value, found := c.Get(PrincipalKey)
+	principal, ok := value.(*entity.Principal)
+	return principal, found && ok && principal != nil
+}
@@ -6,23 +6,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 )
 
+const coreServiceName = "core"
+
 type CoreClient struct {
 	baseURL string
 	client  *http.Client
+	options clientOptions
 }
 
 type Order struct {
-	ID     string `json:"id"`
-	Status string `json:"status"`
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	CustomerID string `json:"customer_id,omitempty"`
 }
 
-func NewCoreClient(baseURL string) *CoreClient {
+func NewCoreClient(baseURL string, opts ...Option) *CoreClient {
+	options := buildOptions(opts)
 	return &CoreClient{
 		baseURL: baseURL,
-		client:  &http.Client{},
+		client:  newHTTPClient(options),
+		options: options,
 	}
 }
 
@@ -30,12 +35,18 @@ func NewCoreClient(baseURL string) *CoreClient {
 func (c *CoreClient) addServiceAuth(req *http.Request) {
 	// Add service-to-service authentication headers
 	req.Header.Set("X-Service-Name", "payment-service")
-	if apiKey := os.Getenv("PAYMENT_SERVICE_API_KEY"); apiKey != "" {
-		req.Header.Set("X-Service-Key", apiKey)
+	if key := c.options.serviceKey.Value(); key != "" {
+		req.Header.Set("X-Service-Key", key)
 	}
 }
 
 func (c *CoreClient) FindByID(ctx context.Context, orderID string) (Order, error) {
+	return call(c.options, func() (Order, error) {
+		return c.findByID(ctx, orderID)
+	})
+}
+
+func (c *CoreClient) findByID(ctx context.Context, orderID string) (Order, error) {
 	url := fmt.Sprintf("%s/admin/orders/%s", c.baseURL, orderID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -50,12 +61,12 @@ func (c *CoreClient) FindByID(ctx context.Context, orderID string) (Order, error
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return Order{}, err
+		return Order{}, unavailable(coreServiceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return Order{}, fmt.Errorf("failed to get order: status %d", resp.StatusCode)
+		return Order{}, statusError(coreServiceName, "get order", resp.StatusCode)
 	}
 
 	var order Order
@@ -67,6 +78,12 @@ func (c *CoreClient) FindByID(ctx context.Context, orderID string) (Order, error
 }
 
 func (c *CoreClient) Update(ctx context.Context, order Order) (Order, error) {
+	return call(c.options, func() (Order, error) {
+		return c.update(ctx, order)
+	})
+}
+
+func (c *CoreClient) update(ctx context.Context, order Order) (Order, error) {
 	url := fmt.Sprintf("%s/admin/orders/%s", c.baseURL, order.ID)
 
 	payload := map[string]string{
@@ -90,12 +107,12 @@ func (c *CoreClient) Update(ctx context.Context, order Order) (Order, error) {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return Order{}, err
+		return Order{}, unavailable(coreServiceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return Order{}, fmt.Errorf("failed to update order: status %d", resp.StatusCode)
+		return Order{}, statusError(coreServiceName, "update order", resp.StatusCode)
 	}
 
 	var updatedOrder Order
@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+func TestCoreClient_FindByID(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedOrder Order
+		expectedErr   any
+	}{
+		{
+			name:          "Given the order exists, it should decode it",
+			status:        http.StatusOK,
+			body:          `{"id": "order-1", "status": "received", "customer_id": "customer-1"}`,
+			expectedOrder: Order{ID: "order-1", Status: "received", CustomerID: "customer-1"},
+		},
+		{name: "Given a 404, it should return not found", status: http.StatusNotFound, expectedErr: &apperror.NotFoundError{}},
+		{name: "Given a 409, it should return a conflict", status: http.StatusConflict, expectedErr: &apperror.ConflictError{}},
+		{name: "Given a 400, it should return a validation error", status: http.StatusBadRequest, expectedErr: &apperror.ValidationError{}},
+		{name: "Given a 503, it should report core as unavailable", status: http.StatusServiceUnavailable, expectedErr: &apperror.ProviderUnavailableError{}},
+		{name: "Given a 429, it should report core as unavailable", status: http.StatusTooManyRequests, expectedErr: &apperror.ProviderUnavailableError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/admin/orders/order-1", r.URL.Path)
+				assert.Equal(t, "payment-service", r.Header.Get("X-Service-Name"))
+				assert.Equal(t, "core-key", r.Header.Get("X-Service-Key"))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewCoreClient(server.URL, WithServiceKey(secrets.Static("core service key", "core-key")))
+			order, err := client.FindByID(context.Background(), "order-1")
+
+			if tt.expectedErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedOrder, order)
+				return
+			}
+			assert.IsType(t, tt.expectedErr, err)
+			assert.Contains(t, err.Error(), "get order")
+		})
+	}
+}
+
+func TestCoreClient_Update(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	_, err := NewCoreClient(server.URL).Update(context.Background(), Order{ID: "order-1", Status: "paid"})
+
+	var conflictErr *apperror.ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func TestCoreClient_ClientErrorsDoNotOpenBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	breaker := circuitbreaker.New("core", circuitbreaker.Settings{FailureThreshold: 1})
+	client := NewCoreClient(server.URL, WithBreaker(breaker))
+
+	for range 3 {
+		_, err := client.FindByID(context.Background(), "missing")
+		var notFoundErr *apperror.NotFoundError
+		assert.ErrorAs(t, err, &notFoundErr)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.Status().State)
+}
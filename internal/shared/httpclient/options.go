@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/logger"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/signing"
+)
+
+// Option customizes an HTTP client at construction time.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	breaker    *circuitbreaker.Breaker
+	signer     *signing.Signer
+	tlsConfig  *tls.Config
+	wrappers   []func(http.RoundTripper) http.RoundTripper
+	serviceKey *secrets.Secret
+}
+
+// WithBreaker routes every call of the client through the circuit breaker.
+func WithBreaker(breaker *circuitbreaker.Breaker) Option {
+	return func(o *clientOptions) {
+		o.breaker = breaker
+	}
+}
+
+// WithSigner HMAC-signs every outbound request.
+func WithSigner(signer *signing.Signer) Option {
+	return func(o *clientOptions) {
+		o.signer = signer
+	}
+}
+
+// WithServiceKey sends the current value of key as X-Service-Key on the
+// calls that authenticate as this service.
+func WithServiceKey(key *secrets.Secret) Option {
+	return func(o *clientOptions) {
+		o.serviceKey = key
+	}
+}
+
+// WithTLSConfig sets the TLS configuration, e.g. to present an mTLS client
+// certificate.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *clientOptions) {
+		o.tlsConfig = tlsConfig
+	}
+}
+
+// WithTransport wraps the client's transport, e.g. to instrument calls.
+func WithTransport(wrap func(http.RoundTripper) http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.wrappers = append(o.wrappers, wrap)
+	}
+}
+
+func buildOptions(opts []Option) clientOptions {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+func newHTTPClient(options clientOptions) *http.Client {
+	var base http.RoundTripper = http.DefaultTransport
+	if options.tlsConfig != nil {
+		tlsTransport := http.DefaultTransport.(*http.Transport).Clone()
+		tlsTransport.TLSClientConfig = options.tlsConfig
+		base = tlsTransport
+	}
+
+	for _, wrap := range options.wrappers {
+		base = wrap(base)
+	}
+
+	var transport http.RoundTripper = &logger.Transport{Base: base}
+	if options.signer != nil {
+		transport = &signing.Transport{Base: transport, Signer: options.signer}
+	}
+	return &http.Client{Transport: transport}
+}
+
+func call[T any](options clientOptions, fn func() (T, error)) (T, error) {
+	if options.breaker == nil {
+		return fn()
+	}
+	return circuitbreaker.Call(options.breaker, fn)
+}
+
+// unavailable reports a transport failure as the dependency being unavailable.
+func unavailable(service string, err error) error {
+	return &apperror.ProviderUnavailableError{Provider: service, Msg: err.Error()}
+}
+
+// statusError maps an unexpected response status to an error; 5xx and 429
+// mean the dependency is unavailable. 400, 404 and 409 keep their meaning,
+// e.g. an unknown order is a 404 for our caller too, and do not count as
+// breaker failures.
+func statusError(service, operation string, status int) error {
+	msg := fmt.Sprintf("failed to %s: status %d", operation, status)
+	switch {
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		return &apperror.ProviderUnavailableError{Provider: service, Msg: msg}
+	case status == http.StatusNotFound:
+		return &apperror.NotFoundError{Msg: msg}
+	case status == http.StatusConflict:
+		return &apperror.ConflictError{Msg: msg}
+	case status == http.StatusBadRequest:
+		return &apperror.ValidationError{Msg: msg}
+	}
+	return errors.New(msg)
+}
@@ -8,9 +8,12 @@ import (
 	"net/http"
 )
 
+const productServiceName = "product"
+
 type ProductClient struct {
 	baseURL string
 	client  *http.Client
+	options clientOptions
 }
 
 type Product struct {
@@ -20,14 +23,22 @@ type Product struct {
 	PreparingTime uint    `json:"preparing_time"`
 }
 
-func NewProductClient(baseURL string) *ProductClient {
+func NewProductClient(baseURL string, opts ...Option) *ProductClient {
+	options := buildOptions(opts)
 	return &ProductClient{
 		baseURL: baseURL,
-		client:  &http.Client{},
+		client:  newHTTPClient(options),
+		options: options,
 	}
 }
 
 func (c *ProductClient) FindByIDs(ctx context.Context, productIDs []string) ([]Product, error) {
+	return call(c.options, func() ([]Product, error) {
+		return c.findByIDs(ctx, productIDs)
+	})
+}
+
+func (c *ProductClient) findByIDs(ctx context.Context, productIDs []string) ([]Product, error) {
 	url := fmt.Sprintf("%s/admin/products/by-ids", c.baseURL)
 
 	payload := map[string][]string{
@@ -48,12 +59,12 @@ func (c *ProductClient) FindByIDs(ctx context.Context, productIDs []string) ([]P
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, unavailable(productServiceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get products: status %d", resp.StatusCode)
+		return nil, statusError(productServiceName, "get products", resp.StatusCode)
 	}
 
 	var products []Product
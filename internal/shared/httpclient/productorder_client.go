@@ -7,9 +7,12 @@ import (
 	"net/http"
 )
 
+const productOrderServiceName = "product-order"
+
 type ProductOrderClient struct {
 	baseURL string
 	client  *http.Client
+	options clientOptions
 }
 
 type ProductOrder struct {
@@ -20,14 +23,22 @@ type ProductOrder struct {
 	UnitPrice float64 `json:"unit_price"`
 }
 
-func NewProductOrderClient(baseURL string) *ProductOrderClient {
+func NewProductOrderClient(baseURL string, opts ...Option) *ProductOrderClient {
+	options := buildOptions(opts)
 	return &ProductOrderClient{
 		baseURL: baseURL,
-		client:  &http.Client{},
+		client:  newHTTPClient(options),
+		options: options,
 	}
 }
 
 func (c *ProductOrderClient) FindByOrderID(ctx context.Context, orderID string) ([]ProductOrder, error) {
+	return call(c.options, func() ([]ProductOrder, error) {
+		return c.findByOrderID(ctx, orderID)
+	})
+}
+
+func (c *ProductOrderClient) findByOrderID(ctx context.Context, orderID string) ([]ProductOrder, error) {
 	url := fmt.Sprintf("%s/admin/orders/%s/products", c.baseURL, orderID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -39,12 +50,12 @@ func (c *ProductOrderClient) FindByOrderID(ctx context.Context, orderID string)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, unavailable(productOrderServiceName, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get product orders: status %d", resp.StatusCode)
+		return nil, statusError(productOrderServiceName, "get product orders", resp.StatusCode)
 	}
 
 	var productOrders []ProductOrder
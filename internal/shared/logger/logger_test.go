@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_AddsRequestIDFromContext(t *testing.T) {
+	var out bytes.Buffer
+	log := New(&out, "info")
+
+	log.InfoContext(WithRequestID(context.Background(), "req-1"), "payment created", "payment_id", "p-1")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "payment created", line["msg"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "p-1", line["payment_id"])
+}
+
+func TestNew_AddsTraceIDFromContext(t *testing.T) {
+	var out bytes.Buffer
+	log := New(&out, "info")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	log.InfoContext(ctx, "payment approved")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+}
+
+func TestNew_HonorsLevel(t *testing.T) {
+	var out bytes.Buffer
+	log := New(&out, "warn")
+
+	log.Info("dropped")
+	log.Warn("kept")
+
+	assert.NotContains(t, out.String(), "dropped")
+	assert.Contains(t, out.String(), "kept")
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"":        slog.LevelInfo,
+		"verbose": slog.LevelInfo,
+	}
+	for level, expected := range tests {
+		assert.Equal(t, expected, ParseLevel(level), level)
+	}
+}
+
+func TestTransport_PropagatesRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(RequestIDHeader)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+	req, err := http.NewRequestWithContext(WithRequestID(context.Background(), "req-42"), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "req-42", received)
+	assert.Empty(t, req.Header.Get(RequestIDHeader), "the caller's request is not mutated")
+}
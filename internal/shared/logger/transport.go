@@ -0,0 +1,25 @@
+package logger
+
+import "net/http"
+
+// Transport forwards the request ID of the request context to the called
+// service.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := RequestID(req.Context())
+	if id == "" || req.Header.Get(RequestIDHeader) != "" {
+		return base.RoundTrip(req)
+	}
+
+	propagated := req.Clone(req.Context())
+	propagated.Header.Set(RequestIDHeader, id)
+	return base.RoundTrip(propagated)
+}
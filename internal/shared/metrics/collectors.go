@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/gateway"
+)
+
+// TokenCacheStatsProvider exposes the auth token cache counters; ok is
+// false when caching is disabled.
+type TokenCacheStatsProvider interface {
+	TokenCacheStats() (stats gateway.TokenCacheStats, ok bool)
+}
+
+var breakerStates = []circuitbreaker.State{
+	circuitbreaker.StateClosed,
+	circuitbreaker.StateOpen,
+	circuitbreaker.StateHalfOpen,
+}
+
+// WatchTokenCache exports the auth token cache counters, read on every
+// scrape. Nothing is exported while caching is disabled.
+func (m *Metrics) WatchTokenCache(cache TokenCacheStatsProvider) {
+	m.registry.MustRegister(&tokenCacheCollector{
+		cache: cache,
+		lookups: prometheus.NewDesc("auth_token_cache_lookups_total",
+			"Token validation cache lookups, by result (hit, negative_hit, miss).", []string{"result"}, nil),
+		entries: prometheus.NewDesc("auth_token_cache_entries",
+			"Tokens held in the validation cache.", nil, nil),
+	})
+}
+
+// WatchBreakers exports the state of every circuit breaker in the registry,
+// including the ones created after this call.
+func (m *Metrics) WatchBreakers(breakers *circuitbreaker.Registry) {
+	m.registry.MustRegister(&breakersCollector{
+		breakers: breakers,
+		state: prometheus.NewDesc("circuit_breaker_state",
+			"1 for the current state of each circuit breaker, 0 for the others.", []string{"breaker", "state"}, nil),
+		failures: prometheus.NewDesc("circuit_breaker_consecutive_failures",
+			"Consecutive failures counted by each circuit breaker.", []string{"breaker"}, nil),
+	})
+}
+
+type tokenCacheCollector struct {
+	cache   TokenCacheStatsProvider
+	lookups *prometheus.Desc
+	entries *prometheus.Desc
+}
+
+func (c *tokenCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lookups
+	ch <- c.entries
+}
+
+func (c *tokenCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, enabled := c.cache.TokenCacheStats()
+	if !enabled {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.lookups, prometheus.CounterValue, float64(stats.Hits), "hit")
+	ch <- prometheus.MustNewConstMetric(c.lookups, prometheus.CounterValue, float64(stats.NegativeHits), "negative_hit")
+	ch <- prometheus.MustNewConstMetric(c.lookups, prometheus.CounterValue, float64(stats.Misses), "miss")
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries))
+}
+
+type breakersCollector struct {
+	breakers *circuitbreaker.Registry
+	state    *prometheus.Desc
+	failures *prometheus.Desc
+}
+
+func (c *breakersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.state
+	ch <- c.failures
+}
+
+func (c *breakersCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.breakers.Statuses() {
+		for _, state := range breakerStates {
+			value := 0.0
+			if status.State == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, value, status.Name, string(state))
+		}
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.GaugeValue, float64(status.ConsecutiveFailures), status.Name)
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/circuitbreaker"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/gateway"
+)
+
+type stubTokenCache struct {
+	stats   gateway.TokenCacheStats
+	enabled bool
+}
+
+func (s stubTokenCache) TokenCacheStats() (gateway.TokenCacheStats, bool) {
+	return s.stats, s.enabled
+}
+
+func TestWatchTokenCache(t *testing.T) {
+	t.Run("Given caching enabled, it should export the counters", func(t *testing.T) {
+		m := New()
+		m.WatchTokenCache(stubTokenCache{enabled: true, stats: gateway.TokenCacheStats{Hits: 7, NegativeHits: 2, Misses: 3, Entries: 5}})
+
+		body := scrape(t, m)
+		assert.Contains(t, body, `auth_token_cache_lookups_total{result="hit"} 7`)
+		assert.Contains(t, body, `auth_token_cache_lookups_total{result="negative_hit"} 2`)
+		assert.Contains(t, body, `auth_token_cache_lookups_total{result="miss"} 3`)
+		assert.Contains(t, body, `auth_token_cache_entries 5`)
+	})
+
+	t.Run("Given caching disabled, it should export nothing", func(t *testing.T) {
+		m := New()
+		m.WatchTokenCache(stubTokenCache{})
+
+		assert.NotContains(t, scrape(t, m), "auth_token_cache")
+	})
+}
+
+func TestWatchBreakers(t *testing.T) {
+	m := New()
+	breakers := circuitbreaker.NewRegistry(circuitbreaker.Settings{FailureThreshold: 1})
+	m.WatchBreakers(breakers)
+
+	breakers.Get("core")
+	_ = breakers.Get("mercadopago").Execute(func() error {
+		return &apperror.ProviderUnavailableError{Provider: "mercadopago", Msg: "status 503"}
+	})
+	_ = breakers.Get("core").Execute(func() error { return errors.New("not a dependency failure") })
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `circuit_breaker_state{breaker="core",state="closed"} 1`)
+	assert.Contains(t, body, `circuit_breaker_state{breaker="core",state="open"} 0`)
+	assert.Contains(t, body, `circuit_breaker_state{breaker="mercadopago",state="open"} 1`)
+	assert.Contains(t, body, `circuit_breaker_state{breaker="mercadopago",state="closed"} 0`)
+	assert.Contains(t, body, `circuit_breaker_consecutive_failures{breaker="core"} 0`)
+}
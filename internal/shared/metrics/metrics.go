@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns the Prometheus registry exposed on /metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	httpInFlight        prometheus.Gauge
+
+	outboundRequests        *prometheus.CounterVec
+	outboundRequestDuration *prometheus.HistogramVec
+
+	paymentsCreated  *prometheus.CounterVec
+	paymentsApproved *prometheus.CounterVec
+	paymentsRejected *prometheus.CounterVec
+	paymentsExpired  *prometheus.CounterVec
+	timeToApproval   *prometheus.HistogramVec
+	paymentAmount    *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests served, by route and status.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests being served.",
+		}),
+
+		outboundRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbound_requests_total",
+			Help: "Calls to dependencies, by outcome (2xx, 4xx, 5xx, error...).",
+		}, []string{"dependency", "outcome"}),
+		outboundRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "outbound_request_duration_seconds",
+			Help:    "Latency of calls to dependencies.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"dependency"}),
+
+		paymentsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payments_created_total",
+			Help: "Payments created, by method.",
+		}, []string{"method"}),
+		paymentsApproved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payments_approved_total",
+			Help: "Payments approved, by method.",
+		}, []string{"method"}),
+		paymentsRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payments_rejected_total",
+			Help: "Payments rejected or canceled by the provider, by method.",
+		}, []string{"method"}),
+		paymentsExpired: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payments_expired_total",
+			Help: "Payments that expired before being paid, by method.",
+		}, []string{"method"}),
+		timeToApproval: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "payment_time_to_approval_seconds",
+			Help:    "Time from payment creation to approval.",
+			Buckets: []float64{5, 15, 30, 60, 120, 300, 600, 900, 1800, 3600},
+		}, []string{"method"}),
+		paymentAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payment_amount_total",
+			Help: "Sum of payment amounts, by method and event (created, approved).",
+		}, []string{"method", "event"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpRequestDuration, m.httpInFlight,
+		m.outboundRequests, m.outboundRequestDuration,
+		m.paymentsCreated, m.paymentsApproved, m.paymentsRejected, m.paymentsExpired,
+		m.timeToApproval, m.paymentAmount,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records rate, errors and duration per route template, so
+// /payments/:id is one series regardless of the ID.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.httpInFlight.Inc()
+		defer m.httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveOutbound records one call to a dependency.
+func (m *Metrics) ObserveOutbound(dependency, outcome string, duration time.Duration) {
+	m.outboundRequests.WithLabelValues(dependency, outcome).Inc()
+	m.outboundRequestDuration.WithLabelValues(dependency).Observe(duration.Seconds())
+}
+
+// InstrumentTransport returns a wrapper recording every call made through a
+// transport as calls to dependency.
+func (m *Metrics) InstrumentTransport(dependency string) func(http.RoundTripper) http.RoundTripper {
+	return func(base http.RoundTripper) http.RoundTripper {
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		return &transport{base: base, dependency: dependency, metrics: m}
+	}
+}
+
+type transport struct {
+	base       http.RoundTripper
+	dependency string
+	metrics    *Metrics
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	outcome := "error"
+	if err == nil {
+		outcome = strconv.Itoa(resp.StatusCode/100) + "xx"
+	}
+	t.metrics.ObserveOutbound(t.dependency, outcome, time.Since(start))
+	return resp, err
+}
+
+func (m *Metrics) PaymentCreated(method string, amount float64) {
+	m.paymentsCreated.WithLabelValues(method).Inc()
+	m.paymentAmount.WithLabelValues(method, "created").Add(amount)
+}
+
+// PaymentApproved records an approval; a zero timeToApproval (unknown
+// creation time) is left out of the histogram.
+func (m *Metrics) PaymentApproved(method string, amount float64, timeToApproval time.Duration) {
+	m.paymentsApproved.WithLabelValues(method).Inc()
+	m.paymentAmount.WithLabelValues(method, "approved").Add(amount)
+	if timeToApproval > 0 {
+		m.timeToApproval.WithLabelValues(method).Observe(timeToApproval.Seconds())
+	}
+}
+
+func (m *Metrics) PaymentRejected(method string) {
+	m.paymentsRejected.WithLabelValues(method).Inc()
+}
+
+func (m *Metrics) PaymentExpired(method string) {
+	m.paymentsExpired.WithLabelValues(method).Inc()
+}
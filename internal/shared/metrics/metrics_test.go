@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	resp := httptest.NewRecorder()
+	m.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	return resp.Body.String()
+}
+
+func TestMiddleware_RecordsPerRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/payments/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, id := range []string{"p-1", "p-2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/payments/"+id, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/payments/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/payments/:id"} 2`)
+}
+
+func TestInstrumentTransport_RecordsOutcomePerDependency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	m := New()
+	client := &http.Client{Transport: m.InstrumentTransport("core")(nil)}
+	for _, path := range []string{"/ok", "/fail"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://127.0.0.1:0/unreachable")
+	assert.Error(t, err)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `outbound_requests_total{dependency="core",outcome="2xx"} 1`)
+	assert.Contains(t, body, `outbound_requests_total{dependency="core",outcome="5xx"} 1`)
+	assert.Contains(t, body, `outbound_requests_total{dependency="core",outcome="error"} 1`)
+	assert.Contains(t, body, `outbound_request_duration_seconds_count{dependency="core"} 3`)
+}
+
+func TestPaymentEvents(t *testing.T) {
+	m := New()
+
+	m.PaymentCreated("QR_CODE", 25.5)
+	m.PaymentCreated("CASH", 10)
+	m.PaymentApproved("QR_CODE", 25.5, 42*time.Second)
+	m.PaymentApproved("CASH", 10, 0)
+	m.PaymentRejected("QR_CODE")
+	m.PaymentExpired("QR_CODE")
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `payments_created_total{method="QR_CODE"} 1`)
+	assert.Contains(t, body, `payments_approved_total{method="CASH"} 1`)
+	assert.Contains(t, body, `payments_rejected_total{method="QR_CODE"} 1`)
+	assert.Contains(t, body, `payments_expired_total{method="QR_CODE"} 1`)
+	assert.Contains(t, body, `payment_amount_total{event="approved",method="QR_CODE"} 25.5`)
+	assert.Contains(t, body, `payment_time_to_approval_seconds_sum{method="QR_CODE"} 42`)
+	assert.NotContains(t, body, `payment_time_to_approval_seconds_count{method="CASH"}`)
+}
@@ -0,0 +1,47 @@
+package mtls
+
+import (
+	"crypto/tls"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// Identity maps a client certificate subject common name to a service.
+type Identity struct {
+	Subject string   `mapstructure:"subject" yaml:"subject"`
+	Service string   `mapstructure:"service" yaml:"service"`
+	Scopes  []string `mapstructure:"scopes" yaml:"scopes"`
+}
+
+// Identities resolves verified client certificates to services.
+type Identities struct {
+	bySubject map[string]Identity
+}
+
+func NewIdentities(identities []Identity) *Identities {
+	bySubject := make(map[string]Identity, len(identities))
+	for _, identity := range identities {
+		bySubject[identity.Subject] = identity
+	}
+	return &Identities{bySubject: bySubject}
+}
+
+// Resolve returns the service of the verified client certificate, if the
+// connection carries one. present reports whether a verified certificate
+// was found at all, so an unmapped certificate can be told apart from none.
+func (i *Identities) Resolve(state *tls.ConnectionState) (claims *entity.ServiceClaims, present bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	subject := state.VerifiedChains[0][0].Subject.CommonName
+	identity, found := i.bySubject[subject]
+	if !found {
+		return nil, true
+	}
+	return &entity.ServiceClaims{
+		ServiceName: identity.Service,
+		KeyID:       "cert:" + subject,
+		Scopes:      identity.Scopes,
+	}, true
+}
@@ -0,0 +1,85 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ServerConfig holds the files used to serve HTTPS and verify client
+// certificates.
+type ServerConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// RequireClientCert rejects connections without a valid client
+	// certificate; otherwise one is verified only when presented, so user
+	// traffic can keep using JWTs on the same listener.
+	RequireClientCert bool
+}
+
+// ClientConfig holds the files a client uses to present its certificate and
+// verify the server.
+type ClientConfig struct {
+	CertFile string
+	KeyFile  string
+	// CAFile verifies the server; empty uses the system roots.
+	CAFile string
+}
+
+func NewServerTLSConfig(config ServerConfig) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	if config.ClientCAFile == "" {
+		return nil, errors.New("client CA file is required for mTLS")
+	}
+	clientCAs, err := loadCertPool(config.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if config.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   clientAuth,
+	}, nil
+}
+
+func NewClientTLSConfig(config ClientConfig) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}
+	if config.CAFile != "" {
+		if tlsConfig.RootCAs, err = loadCertPool(config.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,170 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, filepath.Join(ca.dir, "ca.crt"), "CERTIFICATE", der)
+	return ca
+}
+
+// issue writes a certificate for commonName signed by the CA and returns
+// the cert and key paths.
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(ca.dir, commonName+".crt")
+	keyFile := filepath.Join(ca.dir, commonName+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func newMTLSServer(t *testing.T, ca *testCA, identities *Identities) *httptest.Server {
+	t.Helper()
+	certFile, keyFile := ca.issue(t, "payment-service", x509.ExtKeyUsageServerAuth)
+	tlsConfig, err := NewServerTLSConfig(ServerConfig{
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		ClientCAFile:      filepath.Join(ca.dir, "ca.crt"),
+		RequireClientCert: true,
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := identities.Resolve(r.TLS)
+		if claims == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, claims.ServiceName)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	identities := NewIdentities([]Identity{{Subject: "core-service", Service: "core-service", Scopes: []string{"payments:read"}}})
+	server := newMTLSServer(t, ca, identities)
+
+	t.Run("Given a client certificate from the CA, it should resolve the service identity", func(t *testing.T) {
+		certFile, keyFile := ca.issue(t, "core-service", x509.ExtKeyUsageClientAuth)
+		tlsConfig, err := NewClientTLSConfig(ClientConfig{CertFile: certFile, KeyFile: keyFile, CAFile: filepath.Join(ca.dir, "ca.crt")})
+		require.NoError(t, err)
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "core-service", string(body))
+	})
+
+	t.Run("Given a certificate with an unmapped subject, it should not resolve a service", func(t *testing.T) {
+		certFile, keyFile := ca.issue(t, "unknown-service", x509.ExtKeyUsageClientAuth)
+		tlsConfig, err := NewClientTLSConfig(ClientConfig{CertFile: certFile, KeyFile: keyFile, CAFile: filepath.Join(ca.dir, "ca.crt")})
+		require.NoError(t, err)
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Given no client certificate, it should fail the handshake", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+		_, err := client.Get(server.URL)
+		assert.Error(t, err)
+	})
+
+	t.Run("Given a certificate from another CA, it should fail the handshake", func(t *testing.T) {
+		other := newTestCA(t)
+		certFile, keyFile := other.issue(t, "core-service", x509.ExtKeyUsageClientAuth)
+		tlsConfig, err := NewClientTLSConfig(ClientConfig{CertFile: certFile, KeyFile: keyFile, CAFile: filepath.Join(ca.dir, "ca.crt")})
+		require.NoError(t, err)
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		_, err = client.Get(server.URL)
+		assert.Error(t, err)
+	})
+}
+
+func TestIdentities_ResolveWithoutTLS(t *testing.T) {
+	claims, present := NewIdentities(nil).Resolve(nil)
+
+	assert.Nil(t, claims)
+	assert.False(t, present)
+}
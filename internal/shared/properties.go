@@ -1,7 +1,39 @@
 package shared
 
+// Service credential sources
 const (
-	// Providers
-	MercadoPagoHost       = "app.providers.mercadopago.host"
-	MercadoPagoQRCodePath = "app.providers.mercadopago.qrcode.path"
+	ServiceCredentialsSourceFile  = "file"
+	ServiceCredentialsSourceMongo = "mongo"
+)
+
+// Rate limit backends
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendMongo  = "mongo"
+)
+
+// Where replay-protection nonces of signed service requests are kept
+const (
+	SigningNonceBackendMemory = "memory"
+	SigningNonceBackendMongo  = "mongo"
+)
+
+// Rate limit route groups
+const (
+	RateLimitGroupPaymentsCreate = "payments_create"
+	RateLimitGroupPaymentsAPI    = "payments_api"
+	RateLimitGroupWebhooks       = "webhooks"
+)
+
+// Auth modes: local verifies JWTs in-process, lambda delegates to the
+// serverless auth function.
+const (
+	AuthModeLocal  = "local"
+	AuthModeLambda = "lambda"
+)
+
+// Mercado Pago QR integration modes
+const (
+	MercadoPagoModeInStore = "instore"
+	MercadoPagoModeOrders  = "orders"
 )
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	idleTTL   time.Duration
+}
+
+// MemoryBackend keeps buckets in process memory.
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{buckets: make(map[string]*bucket)}
+}
+
+func (m *MemoryBackend) Take(_ context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	b, found := m.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		m.buckets[key] = b
+	}
+	b.tokens = refill(b.tokens, now.Sub(b.updatedAt), limit)
+	b.updatedAt = now
+	b.idleTTL = limit.idleTTL()
+
+	var decision Decision
+	b.tokens, decision = decide(b.tokens, limit)
+	return decision, nil
+}
+
+// sweep drops full buckets once a minute so idle clients don't accumulate.
+func (m *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	for key, b := range m.buckets {
+		if now.Sub(b.updatedAt) > b.idleTTL {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const bucketsCollection = "rate_limit_buckets"
+
+// MongoBackend keeps buckets in the rate_limit_buckets collection so every
+// replica draws from the same budget. Each take is a single atomic
+// pipeline update; a TTL index removes idle buckets.
+type MongoBackend struct {
+	collection *mongo.Collection
+}
+
+type bucketDocument struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+func NewMongoBackend(ctx context.Context, db *mongo.Database) (*MongoBackend, error) {
+	collection := db.Collection(bucketsCollection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoBackend{collection: collection}, nil
+}
+
+func (m *MongoBackend) Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	burst := float64(limit.Burst)
+	elapsedSeconds := bson.M{"$divide": bson.A{
+		bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}}},
+		1000,
+	}}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{burst, bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$tokens", burst}},
+				bson.M{"$multiply": bson.A{elapsedSeconds, limit.ratePerSecond()}},
+			}}}},
+			"updated_at": now,
+			"expires_at": now.Add(limit.idleTTL()),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+			"tokens": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$tokens", 1}},
+				bson.M{"$subtract": bson.A{"$tokens", 1}},
+				"$tokens",
+			}},
+		}}},
+	}
+
+	var doc bucketDocument
+	err := m.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	if doc.Allowed {
+		return Decision{Allowed: true, Remaining: int(doc.Tokens)}, nil
+	}
+	_, decision := decide(doc.Tokens, limit)
+	return decision, nil
+}
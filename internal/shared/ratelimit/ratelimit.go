@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens, refilled at RequestsPerMinute.
+type Limit struct {
+	RequestsPerMinute float64 `mapstructure:"requests_per_minute"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// ratePerSecond is the refill rate in tokens per second.
+func (l Limit) ratePerSecond() float64 {
+	return l.RequestsPerMinute / 60
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.RequestsPerMinute > 0 && l.Burst > 0
+}
+
+// idleTTL is how long an untouched bucket must be kept: the time it takes
+// to refill completely. After that it is equivalent to a new bucket.
+func (l Limit) idleTTL() time.Duration {
+	return time.Duration(float64(l.Burst) / l.ratePerSecond() * float64(time.Second))
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Backend stores the buckets. MemoryBackend is per replica; MongoBackend is
+// shared so limits hold across replicas.
+type Backend interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error)
+}
+
+// Limiter takes tokens from a backend. Backend failures let the request
+// through: rate limiting must not take the service down with it.
+type Limiter struct {
+	backend Backend
+	now     func() time.Time
+}
+
+func NewLimiter(backend Backend) *Limiter {
+	return &Limiter{backend: backend, now: time.Now}
+}
+
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) Decision {
+	if !limit.Enabled() {
+		return Decision{Allowed: true, Remaining: math.MaxInt32}
+	}
+
+	decision, err := l.backend.Take(ctx, key, limit, l.now())
+	if err != nil {
+		slog.Warn("rate limit backend failed, allowing request", "key", key, "error", err)
+		return Decision{Allowed: true}
+	}
+	return decision
+}
+
+// refill returns the tokens of a bucket after elapsed time, capped at burst.
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.ratePerSecond())
+}
+
+// decide takes a token if one is available and reports the new balance.
+func decide(tokens float64, limit Limit) (float64, Decision) {
+	if tokens >= 1 {
+		tokens--
+		return tokens, Decision{Allowed: true, Remaining: int(tokens)}
+	}
+	wait := (1 - tokens) / limit.ratePerSecond()
+	return tokens, Decision{RetryAfter: time.Duration(wait * float64(time.Second))}
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingBackend struct{}
+
+func (failingBackend) Take(context.Context, string, Limit, time.Time) (Decision, error) {
+	return Decision{}, errors.New("backend down")
+}
+
+func newTestLimiter(backend Backend, now *time.Time) *Limiter {
+	limiter := NewLimiter(backend)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestLimiter(NewMemoryBackend(), &now)
+	limit := Limit{RequestsPerMinute: 60, Burst: 3}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		decision := limiter.Allow(ctx, "totem-1", limit)
+		assert.True(t, decision.Allowed, "request %d within burst", i+1)
+		assert.Equal(t, 2-i, decision.Remaining)
+	}
+
+	denied := limiter.Allow(ctx, "totem-1", limit)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+
+	assert.True(t, limiter.Allow(ctx, "totem-2", limit).Allowed, "other keys have their own bucket")
+
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow(ctx, "totem-1", limit).Allowed, "one token refilled after a second")
+	assert.False(t, limiter.Allow(ctx, "totem-1", limit).Allowed)
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allow(ctx, "totem-1", limit).Allowed, "refill is capped at burst")
+	}
+	assert.False(t, limiter.Allow(ctx, "totem-1", limit).Allowed)
+}
+
+func TestLimiter_DisabledLimit(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(failingBackend{}, &now)
+
+	assert.True(t, limiter.Allow(context.Background(), "key", Limit{}).Allowed)
+}
+
+func TestLimiter_BackendFailureAllows(t *testing.T) {
+	now := time.Now()
+	limiter := newTestLimiter(failingBackend{}, &now)
+
+	assert.True(t, limiter.Allow(context.Background(), "key", Limit{RequestsPerMinute: 1, Burst: 1}).Allowed)
+}
+
+func TestMemoryBackend_SweepsIdleBuckets(t *testing.T) {
+	backend := NewMemoryBackend()
+	limit := Limit{RequestsPerMinute: 60, Burst: 10}
+	now := time.Now()
+
+	_, _ = backend.Take(context.Background(), "idle", limit, now)
+	_, _ = backend.Take(context.Background(), "active", limit, now.Add(2*time.Minute))
+
+	assert.NotContains(t, backend.buckets, "idle")
+	assert.Contains(t, backend.buckets, "active")
+}
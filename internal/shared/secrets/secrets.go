@@ -0,0 +1,132 @@
+// Package secrets holds credentials that can be rotated while the service
+// runs. A Secret backed by a file, e.g. a Kubernetes secret volume, follows
+// it: Watch re-reads the file and swaps the value in place, so consumers
+// that call Value on every use pick up a new token without a restart.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Secret is a named value, fixed or read from a file.
+type Secret struct {
+	name  string
+	path  string
+	value atomic.Value
+}
+
+// Static returns a Secret that never changes.
+func Static(name, value string) *Secret {
+	s := &Secret{name: name}
+	s.value.Store(value)
+	return s
+}
+
+// FromFile reads the secret from path, trimming surrounding whitespace. It
+// fails when the file is missing or empty.
+func FromFile(name, path string) (*Secret, error) {
+	s := &Secret{name: name, path: path}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load returns a Secret read from path when it is set, and value otherwise.
+func Load(name, value, path string) (*Secret, error) {
+	if path == "" {
+		return Static(name, value), nil
+	}
+	return FromFile(name, path)
+}
+
+// Value returns the current value; a nil Secret is empty.
+func (s *Secret) Value() string {
+	if s == nil {
+		return ""
+	}
+	value, _ := s.value.Load().(string)
+	return value
+}
+
+// Reload re-reads the file and reports whether the value changed. A failed
+// read keeps the last good value, so a half-written file cannot blank a
+// credential.
+func (s *Secret) Reload() (bool, error) {
+	if s.path == "" {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", s.name, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return false, fmt.Errorf("%s file %s is empty", s.name, s.path)
+	}
+
+	if value == s.Value() {
+		return false, nil
+	}
+	s.value.Store(value)
+	return true, nil
+}
+
+// Watch reloads the secret every interval until ctx is done. Static
+// secrets return immediately.
+func (s *Secret) Watch(ctx context.Context, interval time.Duration) {
+	if s == nil || s.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := s.Reload()
+			if err != nil {
+				slog.Error("failed to reload secret", "secret", s.name, "error", err)
+				continue
+			}
+			if changed {
+				slog.Info("secret reloaded", "secret", s.name)
+			}
+		}
+	}
+}
+
+// Set is an allow-list of named secrets, e.g. the access tokens a stored
+// reference may select. Names outside it never resolve, so a reference
+// cannot reach arbitrary process environment.
+type Set map[string]*Secret
+
+// Lookup returns the secret registered under name.
+func (s Set) Lookup(name string) (*Secret, bool) {
+	secret, found := s[name]
+	return secret, found
+}
+
+// Watch reloads every file-backed secret of the set until ctx is done.
+func (s Set) Watch(ctx context.Context, interval time.Duration) {
+	var wg sync.WaitGroup
+	for _, secret := range s {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			secret.Watch(ctx, interval)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,116 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecret(t *testing.T, path, value string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(value), 0o600))
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	writeSecret(t, path, "from-file\n")
+
+	tests := []struct {
+		name          string
+		value         string
+		path          string
+		expectedValue string
+		expectedErr   bool
+	}{
+		{name: "Given only a value, it should return it", value: "from-env", expectedValue: "from-env"},
+		{name: "Given a file, it should read it and trim the newline", value: "from-env", path: path, expectedValue: "from-file"},
+		{name: "Given a missing file, it should fail", path: filepath.Join(t.TempDir(), "missing"), expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := Load("token", tt.value, tt.path)
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, secret.Value())
+		})
+	}
+}
+
+func TestSecret_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	writeSecret(t, path, "token-1")
+	secret, err := FromFile("token", path)
+	require.NoError(t, err)
+
+	t.Run("Given an unchanged file, it should report no change", func(t *testing.T) {
+		changed, err := secret.Reload()
+
+		assert.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("Given a rotated file, it should swap the value", func(t *testing.T) {
+		writeSecret(t, path, "token-2")
+
+		changed, err := secret.Reload()
+
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, "token-2", secret.Value())
+	})
+
+	t.Run("Given an emptied file, it should keep the last good value", func(t *testing.T) {
+		writeSecret(t, path, "  \n")
+
+		_, err := secret.Reload()
+
+		assert.Error(t, err)
+		assert.Equal(t, "token-2", secret.Value())
+	})
+
+	t.Run("Given a removed file, it should keep the last good value", func(t *testing.T) {
+		require.NoError(t, os.Remove(path))
+
+		_, err := secret.Reload()
+
+		assert.Error(t, err)
+		assert.Equal(t, "token-2", secret.Value())
+	})
+}
+
+func TestSecret_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	writeSecret(t, path, "token-1")
+	secret, err := FromFile("token", path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		secret.Watch(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	writeSecret(t, path, "token-2")
+	assert.Eventually(t, func() bool { return secret.Value() == "token-2" }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestSecret_NilIsEmpty(t *testing.T) {
+	var secret *Secret
+
+	assert.Equal(t, "", secret.Value())
+	secret.Watch(context.Background(), time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package signing
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const noncesCollection = "signing_nonces"
+
+// MongoNonceStore keeps nonces in the signing_nonces collection so a request
+// replayed against another replica is still caught. A TTL index removes
+// expired nonces.
+type MongoNonceStore struct {
+	collection *mongo.Collection
+	now        func() time.Time
+}
+
+func NewMongoNonceStore(ctx context.Context, db *mongo.Database) (*MongoNonceStore, error) {
+	collection := db.Collection(noncesCollection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MongoNonceStore{collection: collection, now: time.Now}, nil
+}
+
+// Add claims the nonce with a single upsert that only matches an expired
+// entry: a live one makes the upsert insert a duplicate _id, which means
+// the nonce was already used. Expired entries the TTL monitor has not
+// removed yet are taken over.
+func (m *MongoNonceStore) Add(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := m.now()
+	_, err := m.collection.UpdateOne(ctx,
+		bson.M{"_id": nonce, "expires_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"expires_at": now.Add(ttl)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
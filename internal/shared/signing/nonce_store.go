@@ -0,0 +1,57 @@
+package signing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers nonces for replay protection. A shared implementation
+// is needed when several replicas verify requests from the same caller.
+type NonceStore interface {
+	// Add records nonce for ttl and reports false if it was already present.
+	Add(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore keeps nonces in process memory, so it only protects a
+// single replica.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (m *MemoryNonceStore) Add(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now, ttl)
+
+	if expiresAt, found := m.nonces[nonce]; found && now.Before(expiresAt) {
+		return false, nil
+	}
+	m.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// sweep drops expired nonces at most once per ttl so Add stays cheap.
+func (m *MemoryNonceStore) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(m.lastSweep) < ttl {
+		return
+	}
+	for nonce, expiresAt := range m.nonces {
+		if !now.Before(expiresAt) {
+			delete(m.nonces, nonce)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,96 @@
+package signing
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+// Secrets maps a calling service to its signing secret.
+type Secrets map[string][]byte
+
+// LoadSecrets reads a YAML file of service signing secrets:
+//
+//	services:
+//	  core-service: <secret>
+func LoadSecrets(path string) (Secrets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing secrets: %w", err)
+	}
+	return ParseSecrets(data)
+}
+
+// ParseSecrets decodes the YAML format read by LoadSecrets.
+func ParseSecrets(data []byte) (Secrets, error) {
+	var file struct {
+		Services map[string]string `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse signing secrets: %w", err)
+	}
+
+	parsed := make(Secrets, len(file.Services))
+	for service, secret := range file.Services {
+		if secret == "" {
+			return nil, fmt.Errorf("empty signing secret for %s", service)
+		}
+		parsed[service] = []byte(secret)
+	}
+	return parsed, nil
+}
+
+func (s Secrets) Lookup(service string) ([]byte, bool) {
+	secret, found := s[service]
+	return secret, found
+}
+
+// RotatingSecrets serves the signing secrets from a secrets.Secret holding
+// the YAML file, so a Watch on it rotates them without a restart. The file
+// is re-parsed when its content changes; an invalid one keeps the last good
+// secrets.
+type RotatingSecrets struct {
+	source *secrets.Secret
+
+	mu     sync.Mutex
+	raw    string
+	parsed Secrets
+}
+
+// NewRotatingSecrets fails when the current content does not parse.
+func NewRotatingSecrets(source *secrets.Secret) (*RotatingSecrets, error) {
+	raw := source.Value()
+	parsed, err := ParseSecrets([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	return &RotatingSecrets{source: source, raw: raw, parsed: parsed}, nil
+}
+
+func (r *RotatingSecrets) Lookup(service string) ([]byte, bool) {
+	return r.current().Lookup(service)
+}
+
+func (r *RotatingSecrets) current() Secrets {
+	raw := r.source.Value()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if raw == r.raw {
+		return r.parsed
+	}
+
+	parsed, err := ParseSecrets([]byte(raw))
+	if err != nil {
+		slog.Error("keeping previous signing secrets", "error", err)
+	} else {
+		r.parsed = parsed
+	}
+	r.raw = raw
+	return r.parsed
+}
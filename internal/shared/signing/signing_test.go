@@ -0,0 +1,204 @@
+package signing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/secrets"
+)
+
+var testSecrets = Secrets{"core-service": []byte("core-secret")}
+
+func newTestVerifier(now time.Time) *Verifier {
+	verifier := NewVerifier(testSecrets.Lookup, 5*time.Minute, NewMemoryNonceStore())
+	verifier.now = func() time.Time { return now }
+	return verifier
+}
+
+func signedRequest(t *testing.T, signedAt time.Time, body []byte) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/payments?order=1", bytes.NewReader(body))
+	signer := NewSigner([]byte("core-secret"))
+	signer.now = func() time.Time { return signedAt }
+	require.NoError(t, signer.Sign(req, body))
+	return req
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"order_id":"1"}`)
+
+	tests := []struct {
+		name     string
+		request  func(t *testing.T) *http.Request
+		service  string
+		body     []byte
+		expected error
+	}{
+		{
+			name:     "Given a valid signature, it should accept the request",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, now, body) },
+			service:  "core-service",
+			body:     body,
+			expected: nil,
+		},
+		{
+			name:     "Given a tampered body, it should reject the signature",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, now, body) },
+			service:  "core-service",
+			body:     []byte(`{"order_id":"2"}`),
+			expected: ErrInvalidSignature,
+		},
+		{
+			name: "Given a tampered path, it should reject the signature",
+			request: func(t *testing.T) *http.Request {
+				req := signedRequest(t, now, body)
+				req.URL.RawQuery = "order=2"
+				return req
+			},
+			service:  "core-service",
+			body:     body,
+			expected: ErrInvalidSignature,
+		},
+		{
+			name:     "Given a timestamp beyond the skew, it should reject the request as stale",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, now.Add(-6*time.Minute), body) },
+			service:  "core-service",
+			body:     body,
+			expected: ErrStaleSignature,
+		},
+		{
+			name:     "Given a timestamp within the skew, it should accept the request",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, now.Add(4*time.Minute), body) },
+			service:  "core-service",
+			body:     body,
+			expected: nil,
+		},
+		{
+			name: "Given missing signature headers, it should report the request as unsigned",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/payments", bytes.NewReader(body))
+			},
+			service:  "core-service",
+			body:     body,
+			expected: ErrMissingSignature,
+		},
+		{
+			name:     "Given a service without a secret, it should reject the signature",
+			request:  func(t *testing.T) *http.Request { return signedRequest(t, now, body) },
+			service:  "unknown-service",
+			body:     body,
+			expected: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestVerifier(now).Verify(tt.request(t), tt.service, tt.body)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVerifier_RejectsReplayedNonce(t *testing.T) {
+	now := time.Now()
+	verifier := newTestVerifier(now)
+	body := []byte(`{}`)
+	req := signedRequest(t, now, body)
+
+	require.NoError(t, verifier.Verify(req, "core-service", body))
+	assert.ErrorIs(t, verifier.Verify(req, "core-service", body), ErrReplayedNonce)
+}
+
+func TestTransport_SignsRequests(t *testing.T) {
+	verifier := NewVerifier(testSecrets.Lookup, time.Minute, NewMemoryNonceStore())
+	var verifyErr error
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		verifyErr = verifier.Verify(r, "core-service", received)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{Signer: NewSigner([]byte("core-secret"))}}
+	resp, err := client.Post(server.URL+"/orders/1?expand=items", "application/json", bytes.NewReader([]byte(`{"status":"paid"}`)))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.NoError(t, verifyErr)
+	assert.JSONEq(t, `{"status":"paid"}`, string(received))
+}
+
+func TestRotatingSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing-secrets.yml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	write("services:\n  core-service: secret-1\n")
+	source, err := secrets.FromFile("signing secrets", path)
+	require.NoError(t, err)
+	rotating, err := NewRotatingSecrets(source)
+	require.NoError(t, err)
+
+	secret, found := rotating.Lookup("core-service")
+	require.True(t, found)
+	assert.Equal(t, "secret-1", string(secret))
+
+	t.Run("Given a reloaded file, it should serve the new secrets", func(t *testing.T) {
+		write("services:\n  core-service: secret-2\n  operation-service: secret-3\n")
+		_, err := source.Reload()
+		require.NoError(t, err)
+
+		secret, _ := rotating.Lookup("core-service")
+		assert.Equal(t, "secret-2", string(secret))
+		_, found := rotating.Lookup("operation-service")
+		assert.True(t, found)
+	})
+
+	t.Run("Given an invalid file, it should keep the last good secrets", func(t *testing.T) {
+		write("services:\n  core-service: \"\"\n")
+		_, err := source.Reload()
+		require.NoError(t, err)
+
+		secret, _ := rotating.Lookup("core-service")
+		assert.Equal(t, "secret-2", string(secret))
+	})
+}
+
+func TestRotatingSigner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing-secret")
+	require.NoError(t, os.WriteFile(path, []byte("old-secret"), 0o600))
+	secret, err := secrets.FromFile("signing secret", path)
+	require.NoError(t, err)
+	signer := NewRotatingSigner(secret)
+
+	verify := func(key string) error {
+		body := []byte(`{}`)
+		req := httptest.NewRequest(http.MethodPost, "/payments", bytes.NewReader(body))
+		require.NoError(t, signer.Sign(req, body))
+		verifier := NewVerifier(Secrets{"core-service": []byte(key)}.Lookup, time.Minute, NewMemoryNonceStore())
+		return verifier.Verify(req, "core-service", body)
+	}
+
+	assert.NoError(t, verify("old-secret"))
+
+	require.NoError(t, os.WriteFile(path, []byte("new-secret"), 0o600))
+	_, err = secret.Reload()
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, verify("old-secret"), ErrInvalidSignature)
+	assert.NoError(t, verify("new-secret"))
+}
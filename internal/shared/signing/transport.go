@@ -0,0 +1,37 @@
+package signing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// Transport signs every request before handing it to Base.
+type Transport struct {
+	Base   http.RoundTripper
+	Signer *Signer
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	signed := req.Clone(req.Context())
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	if err := t.Signer.Sign(signed, body); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/fiap-161/tc-golunch-payment-service"
+
+// Config selects where spans go. Without Enabled, trace context is still
+// propagated but no spans are recorded or exported.
+type Config struct {
+	Enabled     bool
+	ServiceName string
+	// Endpoint is the OTLP/HTTP collector (host:port); empty uses
+	// OTEL_EXPORTER_OTLP_ENDPOINT or the exporter default.
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+// Setup installs the W3C trace context propagator and, when enabled, a
+// tracer provider exporting over OTLP/HTTP. The returned function flushes
+// pending spans.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporterOpts []otlptracehttp.Option
+	if config.Endpoint != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(config.Endpoint))
+	}
+	if config.Insecure {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start opens a span from the global tracer provider.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// Transport wraps an HTTP transport so every call gets a client span and
+// carries the traceparent header.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
+
+// IDs returns the trace and span IDs of the span in ctx, empty when there is
+// no valid span.
+func IDs(ctx context.Context) (traceID, spanID string) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return "", ""
+	}
+	return spanContext.TraceID().String(), spanContext.SpanID().String()
+}
+
+// LinkTo returns a span option linking to the span identified by stored IDs,
+// or no option when the IDs are missing or malformed.
+func LinkTo(traceID, spanID string) []trace.SpanStartOption {
+	tid, tidErr := trace.TraceIDFromHex(traceID)
+	sid, sidErr := trace.SpanIDFromHex(spanID)
+	if tidErr != nil || sidErr != nil {
+		return nil
+	}
+
+	link := trace.Link{SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})}
+	return []trace.SpanStartOption{trace.WithLinks(link)}
+}
+
+// RecordError marks the span in ctx as failed.
+func RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestIDs(t *testing.T) {
+	newRecorder(t)
+
+	traceID, spanID := IDs(context.Background())
+	assert.Empty(t, traceID)
+	assert.Empty(t, spanID)
+
+	ctx, span := Start(context.Background(), "create")
+	defer span.End()
+
+	traceID, spanID = IDs(ctx)
+	assert.Equal(t, span.SpanContext().TraceID().String(), traceID)
+	assert.Equal(t, span.SpanContext().SpanID().String(), spanID)
+}
+
+func TestLinkTo(t *testing.T) {
+	recorder := newRecorder(t)
+
+	origin, originSpan := Start(context.Background(), "create")
+	originSpan.End()
+	traceID, spanID := IDs(origin)
+
+	t.Run("Given stored IDs, it should link the span to the originating trace", func(t *testing.T) {
+		_, span := Start(context.Background(), "settle", LinkTo(traceID, spanID)...)
+		span.End()
+
+		ended := recorder.Ended()
+		settle := ended[len(ended)-1]
+		require.Len(t, settle.Links(), 1)
+		assert.Equal(t, traceID, settle.Links()[0].SpanContext.TraceID().String())
+		assert.Equal(t, spanID, settle.Links()[0].SpanContext.SpanID().String())
+		assert.NotEqual(t, traceID, settle.SpanContext().TraceID().String())
+	})
+
+	t.Run("Given missing or malformed IDs, it should not add a link", func(t *testing.T) {
+		assert.Empty(t, LinkTo("", ""))
+		assert.Empty(t, LinkTo("not-hex", spanID))
+	})
+}
+
+func TestSetup_PropagatesTraceContext(t *testing.T) {
+	newRecorder(t)
+	shutdown, err := Setup(context.Background(), Config{})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := Start(context.Background(), "outbound")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	res, err := (&http.Client{Transport: Transport(http.DefaultTransport)}).Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	coreentity "github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/entity"
+)
+
+type StoreDAO struct {
+	coreentity.Entity `bson:",inline"`
+	Name              string `json:"name" bson:"name"`
+	ExternalStoreID   string `json:"external_store_id" bson:"external_store_id"`
+	CollectorUserID   string `json:"collector_user_id" bson:"collector_user_id"`
+	CredentialsRef    string `json:"credentials_ref" bson:"credentials_ref,omitempty"`
+	ProviderStoreID   string `json:"provider_store_id" bson:"provider_store_id,omitempty"`
+}
+
+type POSDAO struct {
+	coreentity.Entity `bson:",inline"`
+	StoreID           string `json:"store_id" bson:"store_id"`
+	Name              string `json:"name" bson:"name"`
+	ExternalPosID     string `json:"external_pos_id" bson:"external_pos_id"`
+	CollectorUserID   string `json:"collector_user_id" bson:"collector_user_id"`
+	CredentialsRef    string `json:"credentials_ref" bson:"credentials_ref,omitempty"`
+	ProviderPosID     string `json:"provider_pos_id" bson:"provider_pos_id,omitempty"`
+}
+
+func ToStoreDAO(store entity.Store) StoreDAO {
+	return StoreDAO{
+		Entity:          store.Entity,
+		Name:            store.Name,
+		ExternalStoreID: store.ExternalStoreID,
+		CollectorUserID: store.CollectorUserID,
+		CredentialsRef:  store.CredentialsRef,
+		ProviderStoreID: store.ProviderStoreID,
+	}
+}
+
+func FromStoreDAO(storeDAO StoreDAO) entity.Store {
+	return entity.Store{
+		Entity:          storeDAO.Entity,
+		Name:            storeDAO.Name,
+		ExternalStoreID: storeDAO.ExternalStoreID,
+		CollectorUserID: storeDAO.CollectorUserID,
+		CredentialsRef:  storeDAO.CredentialsRef,
+		ProviderStoreID: storeDAO.ProviderStoreID,
+	}
+}
+
+func ToPOSDAO(pos entity.POS) POSDAO {
+	return POSDAO{
+		Entity:          pos.Entity,
+		StoreID:         pos.StoreID,
+		Name:            pos.Name,
+		ExternalPosID:   pos.ExternalPosID,
+		CollectorUserID: pos.CollectorUserID,
+		CredentialsRef:  pos.CredentialsRef,
+		ProviderPosID:   pos.ProviderPosID,
+	}
+}
+
+func FromPOSDAO(posDAO POSDAO) entity.POS {
+	return entity.POS{
+		Entity:          posDAO.Entity,
+		StoreID:         posDAO.StoreID,
+		Name:            posDAO.Name,
+		ExternalPosID:   posDAO.ExternalPosID,
+		CollectorUserID: posDAO.CollectorUserID,
+		CredentialsRef:  posDAO.CredentialsRef,
+		ProviderPosID:   posDAO.ProviderPosID,
+	}
+}
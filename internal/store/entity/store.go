@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/shared/entity"
+)
+
+// Store is a physical store registered in Mercado Pago.
+type Store struct {
+	entity.Entity
+	Name            string `json:"name"`
+	ExternalStoreID string `json:"external_store_id"`
+	CollectorUserID string `json:"collector_user_id"`
+	// CredentialsRef names the secret holding the collector's access token,
+	// used to manage the store and as the default of its POS. Empty means
+	// the default Mercado Pago credentials.
+	CredentialsRef  string `json:"credentials_ref"`
+	ProviderStoreID string `json:"provider_store_id"`
+}
+
+// POS is a point of sale (a totem or a counter) inside a store. It carries
+// everything needed to address Mercado Pago for that terminal.
+type POS struct {
+	entity.Entity
+	StoreID         string `json:"store_id"`
+	Name            string `json:"name"`
+	ExternalPosID   string `json:"external_pos_id"`
+	CollectorUserID string `json:"collector_user_id"`
+	// CredentialsRef names the secret holding the collector's access token.
+	// Empty means the default Mercado Pago credentials.
+	CredentialsRef string `json:"credentials_ref"`
+	ProviderPosID  string `json:"provider_pos_id"`
+}
+
+func (s Store) Build(name, externalStoreID, collectorUserID, credentialsRef string) Store {
+	return Store{
+		Entity:          newEntity(),
+		Name:            name,
+		ExternalStoreID: externalStoreID,
+		CollectorUserID: collectorUserID,
+		CredentialsRef:  credentialsRef,
+	}
+}
+
+func (p POS) Build(store Store, name, externalPosID, credentialsRef string) POS {
+	return POS{
+		Entity:          newEntity(),
+		StoreID:         store.ID,
+		Name:            name,
+		ExternalPosID:   externalPosID,
+		CollectorUserID: store.CollectorUserID,
+		CredentialsRef:  credentialsRef,
+	}
+}
+
+func newEntity() entity.Entity {
+	now := time.Now()
+
+	return entity.Entity{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
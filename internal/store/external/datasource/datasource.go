@@ -0,0 +1,19 @@
+package datasource
+
+import (
+	"context"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/dto"
+)
+
+type DataSource interface {
+	CreateStore(ctx context.Context, store dto.StoreDAO) (dto.StoreDAO, error)
+	FindStoreByID(ctx context.Context, id string) (dto.StoreDAO, error)
+	UpdateStore(ctx context.Context, store dto.StoreDAO) (dto.StoreDAO, error)
+	GetAllStores(ctx context.Context) ([]dto.StoreDAO, error)
+
+	CreatePOS(ctx context.Context, pos dto.POSDAO) (dto.POSDAO, error)
+	FindPOSByID(ctx context.Context, id string) (dto.POSDAO, error)
+	UpdatePOS(ctx context.Context, pos dto.POSDAO) (dto.POSDAO, error)
+	GetAllPOS(ctx context.Context) ([]dto.POSDAO, error)
+}
@@ -0,0 +1,128 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/dto"
+)
+
+const (
+	storesCollection = "stores"
+	posCollection    = "pos"
+)
+
+type MongoDataSource struct {
+	stores *mongo.Collection
+	pos    *mongo.Collection
+}
+
+func NewMongo(db *mongo.Database) DataSource {
+	return &MongoDataSource{
+		stores: db.Collection(storesCollection),
+		pos:    db.Collection(posCollection),
+	}
+}
+
+func (m *MongoDataSource) CreateStore(ctx context.Context, store dto.StoreDAO) (dto.StoreDAO, error) {
+	if _, err := m.stores.InsertOne(ctx, store); err != nil {
+		return dto.StoreDAO{}, err
+	}
+
+	return store, nil
+}
+
+func (m *MongoDataSource) FindStoreByID(ctx context.Context, id string) (dto.StoreDAO, error) {
+	var store dto.StoreDAO
+	if err := findOne(ctx, m.stores, id, &store, "Store not found"); err != nil {
+		return dto.StoreDAO{}, err
+	}
+
+	return store, nil
+}
+
+func (m *MongoDataSource) UpdateStore(ctx context.Context, store dto.StoreDAO) (dto.StoreDAO, error) {
+	if err := replaceOne(ctx, m.stores, store.ID, store, "Store not found"); err != nil {
+		return dto.StoreDAO{}, err
+	}
+
+	return store, nil
+}
+
+func (m *MongoDataSource) GetAllStores(ctx context.Context) ([]dto.StoreDAO, error) {
+	var stores []dto.StoreDAO
+	if err := findAll(ctx, m.stores, &stores); err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
+func (m *MongoDataSource) CreatePOS(ctx context.Context, pos dto.POSDAO) (dto.POSDAO, error) {
+	if _, err := m.pos.InsertOne(ctx, pos); err != nil {
+		return dto.POSDAO{}, err
+	}
+
+	return pos, nil
+}
+
+func (m *MongoDataSource) FindPOSByID(ctx context.Context, id string) (dto.POSDAO, error) {
+	var pos dto.POSDAO
+	if err := findOne(ctx, m.pos, id, &pos, "POS not found"); err != nil {
+		return dto.POSDAO{}, err
+	}
+
+	return pos, nil
+}
+
+func (m *MongoDataSource) UpdatePOS(ctx context.Context, pos dto.POSDAO) (dto.POSDAO, error) {
+	if err := replaceOne(ctx, m.pos, pos.ID, pos, "POS not found"); err != nil {
+		return dto.POSDAO{}, err
+	}
+
+	return pos, nil
+}
+
+func (m *MongoDataSource) GetAllPOS(ctx context.Context) ([]dto.POSDAO, error) {
+	var pos []dto.POSDAO
+	if err := findAll(ctx, m.pos, &pos); err != nil {
+		return nil, err
+	}
+
+	return pos, nil
+}
+
+func findOne(ctx context.Context, collection *mongo.Collection, id string, result any, notFoundMsg string) error {
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &apperror.NotFoundError{Msg: notFoundMsg}
+	}
+
+	return err
+}
+
+func replaceOne(ctx context.Context, collection *mongo.Collection, id string, document any, notFoundMsg string) error {
+	res, err := collection.ReplaceOne(ctx, bson.M{"_id": id}, document)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return &apperror.NotFoundError{Msg: notFoundMsg}
+	}
+
+	return nil
+}
+
+func findAll(ctx context.Context, collection *mongo.Collection, results any) error {
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/external/datasource"
+)
+
+type Gateway struct {
+	datasource datasource.DataSource
+}
+
+func Build(datasource datasource.DataSource) *Gateway {
+	return &Gateway{
+		datasource: datasource,
+	}
+}
+
+func (g *Gateway) CreateStore(c context.Context, store entity.Store) (entity.Store, error) {
+	created, err := g.datasource.CreateStore(c, dto.ToStoreDAO(store))
+	if err != nil {
+		return entity.Store{}, &apperror.InternalError{Msg: err.Error()}
+	}
+
+	return dto.FromStoreDAO(created), nil
+}
+
+func (g *Gateway) FindStoreByID(c context.Context, id string) (entity.Store, error) {
+	found, err := g.datasource.FindStoreByID(c, id)
+	if err != nil {
+		return entity.Store{}, translateError(err)
+	}
+
+	return dto.FromStoreDAO(found), nil
+}
+
+func (g *Gateway) UpdateStore(c context.Context, store entity.Store) (entity.Store, error) {
+	updated, err := g.datasource.UpdateStore(c, dto.ToStoreDAO(store))
+	if err != nil {
+		return entity.Store{}, translateError(err)
+	}
+
+	return dto.FromStoreDAO(updated), nil
+}
+
+func (g *Gateway) ListStores(c context.Context) ([]entity.Store, error) {
+	found, err := g.datasource.GetAllStores(c)
+	if err != nil {
+		return nil, &apperror.InternalError{Msg: err.Error()}
+	}
+
+	stores := make([]entity.Store, 0, len(found))
+	for _, dao := range found {
+		stores = append(stores, dto.FromStoreDAO(dao))
+	}
+	return stores, nil
+}
+
+func (g *Gateway) CreatePOS(c context.Context, pos entity.POS) (entity.POS, error) {
+	created, err := g.datasource.CreatePOS(c, dto.ToPOSDAO(pos))
+	if err != nil {
+		return entity.POS{}, &apperror.InternalError{Msg: err.Error()}
+	}
+
+	return dto.FromPOSDAO(created), nil
+}
+
+func (g *Gateway) FindPOSByID(c context.Context, id string) (entity.POS, error) {
+	found, err := g.datasource.FindPOSByID(c, id)
+	if err != nil {
+		return entity.POS{}, translateError(err)
+	}
+
+	return dto.FromPOSDAO(found), nil
+}
+
+func (g *Gateway) UpdatePOS(c context.Context, pos entity.POS) (entity.POS, error) {
+	updated, err := g.datasource.UpdatePOS(c, dto.ToPOSDAO(pos))
+	if err != nil {
+		return entity.POS{}, translateError(err)
+	}
+
+	return dto.FromPOSDAO(updated), nil
+}
+
+func (g *Gateway) ListPOS(c context.Context) ([]entity.POS, error) {
+	found, err := g.datasource.GetAllPOS(c)
+	if err != nil {
+		return nil, &apperror.InternalError{Msg: err.Error()}
+	}
+
+	pos := make([]entity.POS, 0, len(found))
+	for _, dao := range found {
+		pos = append(pos, dto.FromPOSDAO(dao))
+	}
+	return pos, nil
+}
+
+func translateError(err error) error {
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return notFoundErr
+	}
+	return &apperror.InternalError{Msg: "Unexpected error"}
+}
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/external"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/gateway"
+)
+
+// UseCases provisions stores and POS terminals on Mercado Pago and keeps our
+// datastore in sync with what was created there.
+type UseCases struct {
+	storeGateway *gateway.Gateway
+	provisioner  external.StoreProvisioner
+}
+
+func Build(storeGateway *gateway.Gateway, provisioner external.StoreProvisioner) *UseCases {
+	return &UseCases{
+		storeGateway: storeGateway,
+		provisioner:  provisioner,
+	}
+}
+
+func (u *UseCases) CreateStore(ctx context.Context, params entities.ProvisionStoreParams) (entity.Store, error) {
+	if params.CollectorUserID == "" || params.Name == "" || params.ExternalID == "" {
+		return entity.Store{}, &apperror.ValidationError{Msg: "collector user ID, name and external ID are required"}
+	}
+
+	provisioned, err := u.provisioner.CreateStore(ctx, params)
+	if err != nil {
+		return entity.Store{}, err
+	}
+
+	var store entity.Store
+	store = store.Build(params.Name, params.ExternalID, params.CollectorUserID, params.CredentialsRef)
+	store.ProviderStoreID = provisioned.ID
+
+	return u.storeGateway.CreateStore(ctx, store)
+}
+
+func (u *UseCases) UpdateStore(ctx context.Context, storeID string, params entities.ProvisionStoreParams) (entity.Store, error) {
+	store, err := u.storeGateway.FindStoreByID(ctx, storeID)
+	if err != nil {
+		return entity.Store{}, err
+	}
+
+	if params.Name == "" {
+		params.Name = store.Name
+	}
+	params.ExternalID = store.ExternalStoreID
+	params.CredentialsRef = store.CredentialsRef
+
+	if _, err := u.provisioner.UpdateStore(ctx, store.CollectorUserID, store.ProviderStoreID, params); err != nil {
+		return entity.Store{}, err
+	}
+
+	store.Name = params.Name
+	store.UpdatedAt = time.Now()
+
+	return u.storeGateway.UpdateStore(ctx, store)
+}
+
+// ListStores returns the stores Mercado Pago knows for the collector along
+// with the ones recorded locally.
+func (u *UseCases) ListStores(ctx context.Context, collectorUserID, credentialsRef string) ([]entities.ProvisionedStore, []entity.Store, error) {
+	provisioned, err := u.provisioner.ListStores(ctx, collectorUserID, credentialsRef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	local, err := u.storeGateway.ListStores(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provisioned, local, nil
+}
+
+// CreatePOS provisions the POS with its own credentials, which default to
+// the store's, so a token that cannot manage the store fails here rather
+// than on the first payment.
+func (u *UseCases) CreatePOS(ctx context.Context, storeID, name, externalPosID, credentialsRef string) (entity.POS, error) {
+	if name == "" || externalPosID == "" {
+		return entity.POS{}, &apperror.ValidationError{Msg: "name and external POS ID are required"}
+	}
+
+	store, err := u.storeGateway.FindStoreByID(ctx, storeID)
+	if err != nil {
+		return entity.POS{}, err
+	}
+	if credentialsRef == "" {
+		credentialsRef = store.CredentialsRef
+	}
+
+	provisioned, err := u.provisioner.CreatePOS(ctx, entities.ProvisionPOSParams{
+		Name:            name,
+		ProviderStoreID: store.ProviderStoreID,
+		ExternalStoreID: store.ExternalStoreID,
+		ExternalID:      externalPosID,
+		CredentialsRef:  credentialsRef,
+	})
+	if err != nil {
+		return entity.POS{}, err
+	}
+
+	var pos entity.POS
+	pos = pos.Build(store, name, externalPosID, credentialsRef)
+	pos.ProviderPosID = provisioned.ID
+
+	return u.storeGateway.CreatePOS(ctx, pos)
+}
+
+func (u *UseCases) UpdatePOS(ctx context.Context, posID, name, credentialsRef string) (entity.POS, error) {
+	pos, err := u.storeGateway.FindPOSByID(ctx, posID)
+	if err != nil {
+		return entity.POS{}, err
+	}
+
+	if name != "" && name != pos.Name {
+		if _, err := u.provisioner.UpdatePOS(ctx, pos.ProviderPosID, entities.ProvisionPOSParams{
+			Name:           name,
+			CredentialsRef: pos.CredentialsRef,
+		}); err != nil {
+			return entity.POS{}, err
+		}
+		pos.Name = name
+	}
+	if credentialsRef != "" {
+		pos.CredentialsRef = credentialsRef
+	}
+	pos.UpdatedAt = time.Now()
+
+	return u.storeGateway.UpdatePOS(ctx, pos)
+}
+
+// ListPOS returns the POS Mercado Pago knows for the store along with the
+// ones recorded locally.
+func (u *UseCases) ListPOS(ctx context.Context, externalStoreID, credentialsRef string) ([]entities.ProvisionedPOS, []entity.POS, error) {
+	provisioned, err := u.provisioner.ListPOS(ctx, externalStoreID, credentialsRef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	local, err := u.storeGateway.ListPOS(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provisioned, local, nil
+}
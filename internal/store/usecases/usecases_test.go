@@ -0,0 +1,359 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fiap-161/tc-golunch-payment-service/internal/qrcodeproviders/entities"
+	apperror "github.com/fiap-161/tc-golunch-payment-service/internal/shared/errors"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/dto"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/entity"
+	"github.com/fiap-161/tc-golunch-payment-service/internal/store/gateway"
+)
+
+var anyContext = mock.MatchedBy(func(context.Context) bool { return true })
+
+type mockDataSource struct {
+	mock.Mock
+}
+
+func (m *mockDataSource) CreateStore(ctx context.Context, store dto.StoreDAO) (dto.StoreDAO, error) {
+	args := m.Called(ctx, store)
+	return store, args.Error(0)
+}
+
+func (m *mockDataSource) FindStoreByID(ctx context.Context, id string) (dto.StoreDAO, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.StoreDAO), args.Error(1)
+}
+
+func (m *mockDataSource) UpdateStore(ctx context.Context, store dto.StoreDAO) (dto.StoreDAO, error) {
+	args := m.Called(ctx, store)
+	return store, args.Error(0)
+}
+
+func (m *mockDataSource) GetAllStores(ctx context.Context) ([]dto.StoreDAO, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]dto.StoreDAO), args.Error(1)
+}
+
+func (m *mockDataSource) CreatePOS(ctx context.Context, pos dto.POSDAO) (dto.POSDAO, error) {
+	args := m.Called(ctx, pos)
+	return pos, args.Error(0)
+}
+
+func (m *mockDataSource) FindPOSByID(ctx context.Context, id string) (dto.POSDAO, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(dto.POSDAO), args.Error(1)
+}
+
+func (m *mockDataSource) UpdatePOS(ctx context.Context, pos dto.POSDAO) (dto.POSDAO, error) {
+	args := m.Called(ctx, pos)
+	return pos, args.Error(0)
+}
+
+func (m *mockDataSource) GetAllPOS(ctx context.Context) ([]dto.POSDAO, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]dto.POSDAO), args.Error(1)
+}
+
+type mockProvisioner struct {
+	mock.Mock
+}
+
+func (m *mockProvisioner) CreateStore(ctx context.Context, params entities.ProvisionStoreParams) (entities.ProvisionedStore, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(entities.ProvisionedStore), args.Error(1)
+}
+
+func (m *mockProvisioner) UpdateStore(ctx context.Context, collectorUserID, providerStoreID string, params entities.ProvisionStoreParams) (entities.ProvisionedStore, error) {
+	args := m.Called(ctx, collectorUserID, providerStoreID, params)
+	return args.Get(0).(entities.ProvisionedStore), args.Error(1)
+}
+
+func (m *mockProvisioner) ListStores(ctx context.Context, collectorUserID, credentialsRef string) ([]entities.ProvisionedStore, error) {
+	args := m.Called(ctx, collectorUserID, credentialsRef)
+	return args.Get(0).([]entities.ProvisionedStore), args.Error(1)
+}
+
+func (m *mockProvisioner) CreatePOS(ctx context.Context, params entities.ProvisionPOSParams) (entities.ProvisionedPOS, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(entities.ProvisionedPOS), args.Error(1)
+}
+
+func (m *mockProvisioner) UpdatePOS(ctx context.Context, providerPosID string, params entities.ProvisionPOSParams) (entities.ProvisionedPOS, error) {
+	args := m.Called(ctx, providerPosID, params)
+	return args.Get(0).(entities.ProvisionedPOS), args.Error(1)
+}
+
+func (m *mockProvisioner) ListPOS(ctx context.Context, externalStoreID, credentialsRef string) ([]entities.ProvisionedPOS, error) {
+	args := m.Called(ctx, externalStoreID, credentialsRef)
+	return args.Get(0).([]entities.ProvisionedPOS), args.Error(1)
+}
+
+func newUseCases() (*UseCases, *mockDataSource, *mockProvisioner) {
+	ds := &mockDataSource{}
+	provisioner := &mockProvisioner{}
+	return Build(gateway.Build(ds), provisioner), ds, provisioner
+}
+
+var existingStore = dto.StoreDAO{
+	Name:            "GoLunch Paulista",
+	ExternalStoreID: "STORE001",
+	CollectorUserID: "collector-1",
+	ProviderStoreID: "1234567",
+}
+
+func TestUseCases_CreateStore(t *testing.T) {
+	params := entities.ProvisionStoreParams{
+		CollectorUserID: "collector-1",
+		Name:            "GoLunch Paulista",
+		ExternalID:      "STORE001",
+		CredentialsRef:  "store2",
+		Location:        &entities.Location{CityName: "São Paulo"},
+	}
+
+	t.Run("Given a missing external ID, it should fail before calling the provider", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		invalid := params
+		invalid.ExternalID = ""
+
+		_, err := u.CreateStore(context.Background(), invalid)
+
+		var validationErr *apperror.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		provisioner.AssertNotCalled(t, "CreateStore", mock.Anything, mock.Anything)
+		ds.AssertNotCalled(t, "CreateStore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given the provider creates the store, it should record its ID", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		provisioner.On("CreateStore", anyContext, params).Return(entities.ProvisionedStore{ID: "1234567"}, nil)
+		ds.On("CreateStore", anyContext, mock.MatchedBy(func(store dto.StoreDAO) bool {
+			return store.ProviderStoreID == "1234567" && store.ExternalStoreID == "STORE001" &&
+				store.CollectorUserID == "collector-1" && store.CredentialsRef == "store2"
+		})).Return(nil)
+
+		store, err := u.CreateStore(context.Background(), params)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, store.ID)
+		assert.Equal(t, "1234567", store.ProviderStoreID)
+		ds.AssertExpectations(t)
+	})
+
+	t.Run("Given the provider fails, it should not record the store", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		providerErr := errors.New("provider down")
+		provisioner.On("CreateStore", anyContext, params).Return(entities.ProvisionedStore{}, providerErr)
+
+		_, err := u.CreateStore(context.Background(), params)
+
+		assert.ErrorIs(t, err, providerErr)
+		ds.AssertNotCalled(t, "CreateStore", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUseCases_UpdateStore(t *testing.T) {
+	t.Run("Given only a location, it should keep the name and external ID", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		location := &entities.Location{StreetName: "Av. Paulista"}
+		ds.On("FindStoreByID", anyContext, "store-1").Return(existingStore, nil)
+		provisioner.On("UpdateStore", anyContext, "collector-1", "1234567", entities.ProvisionStoreParams{
+			Name:       "GoLunch Paulista",
+			ExternalID: "STORE001",
+			Location:   location,
+		}).Return(entities.ProvisionedStore{}, nil)
+		ds.On("UpdateStore", anyContext, mock.Anything).Return(nil)
+
+		store, err := u.UpdateStore(context.Background(), "store-1", entities.ProvisionStoreParams{Location: location})
+
+		require.NoError(t, err)
+		assert.Equal(t, "GoLunch Paulista", store.Name)
+		provisioner.AssertExpectations(t)
+	})
+
+	t.Run("Given only a name, it should not send a location", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		ds.On("FindStoreByID", anyContext, "store-1").Return(existingStore, nil)
+		provisioner.On("UpdateStore", anyContext, "collector-1", "1234567", entities.ProvisionStoreParams{
+			Name:       "GoLunch Augusta",
+			ExternalID: "STORE001",
+		}).Return(entities.ProvisionedStore{}, nil)
+		ds.On("UpdateStore", anyContext, mock.MatchedBy(func(store dto.StoreDAO) bool {
+			return store.Name == "GoLunch Augusta"
+		})).Return(nil)
+
+		store, err := u.UpdateStore(context.Background(), "store-1", entities.ProvisionStoreParams{Name: "GoLunch Augusta"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "GoLunch Augusta", store.Name)
+		provisioner.AssertExpectations(t)
+		ds.AssertExpectations(t)
+	})
+
+	t.Run("Given a store with its own credentials, it should update it with them", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		store := existingStore
+		store.CredentialsRef = "store2"
+		ds.On("FindStoreByID", anyContext, "store-1").Return(store, nil)
+		provisioner.On("UpdateStore", anyContext, "collector-1", "1234567", entities.ProvisionStoreParams{
+			Name:           "GoLunch Augusta",
+			ExternalID:     "STORE001",
+			CredentialsRef: "store2",
+		}).Return(entities.ProvisionedStore{}, nil)
+		ds.On("UpdateStore", anyContext, mock.Anything).Return(nil)
+
+		_, err := u.UpdateStore(context.Background(), "store-1", entities.ProvisionStoreParams{Name: "GoLunch Augusta"})
+
+		require.NoError(t, err)
+		provisioner.AssertExpectations(t)
+	})
+
+	t.Run("Given an unknown store, it should return not found", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		ds.On("FindStoreByID", anyContext, "missing").Return(dto.StoreDAO{}, &apperror.NotFoundError{Msg: "Store not found"})
+
+		_, err := u.UpdateStore(context.Background(), "missing", entities.ProvisionStoreParams{Name: "x"})
+
+		var notFoundErr *apperror.NotFoundError
+		assert.ErrorAs(t, err, &notFoundErr)
+		provisioner.AssertNotCalled(t, "UpdateStore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUseCases_ListStores(t *testing.T) {
+	t.Run("Given stores on both sides, it should return both", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		provisioner.On("ListStores", anyContext, "collector-1", "").Return([]entities.ProvisionedStore{{ID: "1234567"}}, nil)
+		ds.On("GetAllStores", anyContext).Return([]dto.StoreDAO{existingStore}, nil)
+
+		provisioned, local, err := u.ListStores(context.Background(), "collector-1", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []entities.ProvisionedStore{{ID: "1234567"}}, provisioned)
+		assert.Equal(t, []entity.Store{dto.FromStoreDAO(existingStore)}, local)
+	})
+
+	t.Run("Given the provider fails, it should return the error", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		providerErr := errors.New("provider down")
+		provisioner.On("ListStores", anyContext, "collector-1", "").Return([]entities.ProvisionedStore(nil), providerErr)
+
+		_, _, err := u.ListStores(context.Background(), "collector-1", "")
+
+		assert.ErrorIs(t, err, providerErr)
+		ds.AssertNotCalled(t, "GetAllStores", mock.Anything)
+	})
+}
+
+func TestUseCases_CreatePOS(t *testing.T) {
+	t.Run("Given a missing name, it should fail before looking up the store", func(t *testing.T) {
+		u, ds, _ := newUseCases()
+
+		_, err := u.CreatePOS(context.Background(), "store-1", "", "STORE001POS001", "")
+
+		var validationErr *apperror.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		ds.AssertNotCalled(t, "FindStoreByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Given a known store, it should provision the POS in it and record the POS", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		store := existingStore
+		store.ID = "store-1"
+		ds.On("FindStoreByID", anyContext, "store-1").Return(store, nil)
+		provisioner.On("CreatePOS", anyContext, entities.ProvisionPOSParams{
+			Name:            "Totem 1",
+			ProviderStoreID: "1234567",
+			ExternalStoreID: "STORE001",
+			ExternalID:      "STORE001POS001",
+			CredentialsRef:  "store2",
+		}).Return(entities.ProvisionedPOS{ID: "987"}, nil)
+		ds.On("CreatePOS", anyContext, mock.Anything).Return(nil)
+
+		pos, err := u.CreatePOS(context.Background(), "store-1", "Totem 1", "STORE001POS001", "store2")
+
+		require.NoError(t, err)
+		assert.Equal(t, "987", pos.ProviderPosID)
+		assert.Equal(t, "store-1", pos.StoreID)
+		assert.Equal(t, "collector-1", pos.CollectorUserID)
+		assert.Equal(t, "store2", pos.CredentialsRef)
+	})
+
+	t.Run("Given no credentials ref, it should use the store's", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		store := existingStore
+		store.ID = "store-1"
+		store.CredentialsRef = "store2"
+		ds.On("FindStoreByID", anyContext, "store-1").Return(store, nil)
+		provisioner.On("CreatePOS", anyContext, mock.MatchedBy(func(params entities.ProvisionPOSParams) bool {
+			return params.CredentialsRef == "store2"
+		})).Return(entities.ProvisionedPOS{ID: "987"}, nil)
+		ds.On("CreatePOS", anyContext, mock.Anything).Return(nil)
+
+		pos, err := u.CreatePOS(context.Background(), "store-1", "Totem 1", "STORE001POS001", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "store2", pos.CredentialsRef)
+		provisioner.AssertExpectations(t)
+	})
+
+	t.Run("Given an unknown store, it should return not found", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		ds.On("FindStoreByID", anyContext, "missing").Return(dto.StoreDAO{}, &apperror.NotFoundError{Msg: "Store not found"})
+
+		_, err := u.CreatePOS(context.Background(), "missing", "Totem 1", "STORE001POS001", "")
+
+		var notFoundErr *apperror.NotFoundError
+		assert.ErrorAs(t, err, &notFoundErr)
+		provisioner.AssertNotCalled(t, "CreatePOS", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUseCases_UpdatePOS(t *testing.T) {
+	existingPOS := dto.POSDAO{Name: "Totem 1", ProviderPosID: "987", CredentialsRef: "store2"}
+
+	t.Run("Given a new name, it should rename the POS on the provider", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		ds.On("FindPOSByID", anyContext, "pos-1").Return(existingPOS, nil)
+		provisioner.On("UpdatePOS", anyContext, "987", entities.ProvisionPOSParams{Name: "Totem 2", CredentialsRef: "store2"}).Return(entities.ProvisionedPOS{}, nil)
+		ds.On("UpdatePOS", anyContext, mock.Anything).Return(nil)
+
+		pos, err := u.UpdatePOS(context.Background(), "pos-1", "Totem 2", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Totem 2", pos.Name)
+		assert.Equal(t, "store2", pos.CredentialsRef)
+		provisioner.AssertExpectations(t)
+	})
+
+	t.Run("Given only a credentials ref, it should not call the provider", func(t *testing.T) {
+		u, ds, provisioner := newUseCases()
+		ds.On("FindPOSByID", anyContext, "pos-1").Return(existingPOS, nil)
+		ds.On("UpdatePOS", anyContext, mock.Anything).Return(nil)
+
+		pos, err := u.UpdatePOS(context.Background(), "pos-1", "Totem 1", "store3")
+
+		require.NoError(t, err)
+		assert.Equal(t, "store3", pos.CredentialsRef)
+		provisioner.AssertNotCalled(t, "UpdatePOS", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUseCases_ListPOS(t *testing.T) {
+	u, ds, provisioner := newUseCases()
+	provisioner.On("ListPOS", anyContext, "STORE001", "store2").Return([]entities.ProvisionedPOS{{ID: "987"}}, nil)
+	ds.On("GetAllPOS", anyContext).Return([]dto.POSDAO{{ProviderPosID: "987"}}, nil)
+
+	provisioned, local, err := u.ListPOS(context.Background(), "STORE001", "store2")
+
+	require.NoError(t, err)
+	assert.Equal(t, []entities.ProvisionedPOS{{ID: "987"}}, provisioned)
+	assert.Equal(t, []entity.POS{{ProviderPosID: "987"}}, local)
+}